@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// ImageRegion is a rectangle of an image to analyze, usually the bounding
+// box of a piece of text.
+type ImageRegion struct {
+	Label  string `json:"label,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Target is the kind of text in the region, smallText unless given.
+	Target Target `json:"target,omitempty"`
+}
+
+func (r ImageRegion) rect() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+// RegionResult is the estimated contrast of the text in one region. The
+// embedded result is named by hex value and has the theme "image".
+type RegionResult struct {
+	ImageRegion
+	// ForegroundShare is the fraction of the region taken up by the
+	// foreground color.
+	ForegroundShare float64 `json:"foregroundShare"`
+	Pass            bool    `json:"pass"`
+	ContrastResult
+}
+
+// ImageAnalysis is the result of analyzing one image.
+type ImageAnalysis struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Tile is the tile size used when no regions were given, and 0
+	// otherwise.
+	Tile    int            `json:"tile,omitempty"`
+	Regions []RegionResult `json:"regions"`
+}
+
+// Limits of image analysis.
+const (
+	defaultTile = 32
+	// maxRegionSamples caps the pixels clustered per region; larger regions
+	// are sampled on a grid.
+	maxRegionSamples = 40000
+	// minForegroundShare and minClusterDistance decide whether a tile holds
+	// text at all, rather than a flat or nearly flat area.
+	minForegroundShare = 0.02
+	minClusterDistance = 12
+)
+
+type rgb [3]float64
+
+func (c rgb) dist(d rgb) float64 {
+	return math.Sqrt((c[0]-d[0])*(c[0]-d[0]) + (c[1]-d[1])*(c[1]-d[1]) + (c[2]-d[2])*(c[2]-d[2]))
+}
+
+func (c rgb) hex() string {
+	return cssColor{c[0], c[1], c[2], 1}.hex()
+}
+
+// regionPixels samples the pixels of r, compositing transparent pixels over
+// white as a browser page would show them.
+func regionPixels(img image.Image, r image.Rectangle) []rgb {
+	step := 1
+	for (r.Dx()/step)*(r.Dy()/step) > maxRegionSamples {
+		step++
+	}
+	var px []rgb
+	for y := r.Min.Y; y < r.Max.Y; y += step {
+		for x := r.Min.X; x < r.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			a := float64(c.A) / 255
+			px = append(px, rgb{
+				float64(c.R)*a + 255*(1-a),
+				float64(c.G)*a + 255*(1-a),
+				float64(c.B)*a + 255*(1-a),
+			})
+		}
+	}
+	return px
+}
+
+// twoColors splits px into two clusters with k-means, starting from the
+// darkest and lightest pixels. The larger cluster is the background, and its
+// most common color is taken as the background color. Anti-aliased edges
+// pull the foreground mean towards the background, so the foreground color
+// is the mean of the tenth of its cluster farthest from the background.
+func twoColors(px []rgb) (fg, bg rgb, share float64) {
+	luma := func(c rgb) float64 { return 0.2126*c[0] + 0.7152*c[1] + 0.0722*c[2] }
+	centers := [2]rgb{px[0], px[0]}
+	for _, p := range px {
+		if luma(p) < luma(centers[0]) {
+			centers[0] = p
+		}
+		if luma(p) > luma(centers[1]) {
+			centers[1] = p
+		}
+	}
+
+	assign := make([]int, len(px))
+	var counts [2]int
+	for iter := 0; iter < 20; iter++ {
+		var sums [2]rgb
+		counts = [2]int{}
+		changed := false
+		for i, p := range px {
+			k := 0
+			if p.dist(centers[1]) < p.dist(centers[0]) {
+				k = 1
+			}
+			if k != assign[i] {
+				assign[i], changed = k, true
+			}
+			counts[k]++
+			for j := range p {
+				sums[k][j] += p[j]
+			}
+		}
+		for k := range centers {
+			if counts[k] > 0 {
+				for j := range sums[k] {
+					centers[k][j] = sums[k][j] / float64(counts[k])
+				}
+			}
+		}
+		if !changed && iter > 0 {
+			break
+		}
+	}
+
+	fgK := 0
+	if counts[1] < counts[0] {
+		fgK = 1
+	}
+	var members []rgb
+	mode := map[rgb]int{}
+	for i, p := range px {
+		if assign[i] == fgK {
+			members = append(members, p)
+		} else {
+			mode[p]++
+		}
+	}
+	for p, n := range mode {
+		if n > mode[bg] || n == mode[bg] && luma(p) > luma(bg) {
+			bg = p
+		}
+	}
+	if len(members) == 0 {
+		return bg, bg, 0
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].dist(bg) > members[j].dist(bg) })
+	members = members[:max(len(members)/10, 1)]
+	for _, p := range members {
+		for j := range p {
+			fg[j] += p[j] / float64(len(members))
+		}
+	}
+	return fg, bg, float64(counts[fgK]) / float64(len(px))
+}
+
+// analyzeRegion estimates the contrast of the text in r.
+func analyzeRegion(img image.Image, r ImageRegion) (RegionResult, rgb, rgb) {
+	fg, bg, share := twoColors(regionPixels(img, r.rect().Add(img.Bounds().Min)))
+	fgHex, bgHex := fg.hex(), bg.hex()
+	ratio, _ := contrastRatio(fgHex, bgHex)
+	result := newContrastResult(fgHex, fgHex, bgHex, bgHex, ratio)
+	result.ForegroundTheme, result.BackgroundTheme = "image", "image"
+	if r.Target == "" {
+		r.Target = TargetSmallText
+	}
+	return RegionResult{
+		ImageRegion:     r,
+		ForegroundShare: math.Round(share*1000) / 1000,
+		Pass:            levelFor(r.Target, ratio) >= LevelAA,
+		ContrastResult:  result,
+	}, fg, bg
+}
+
+// maxImagePixelsDecoded bounds the images accepted for analysis, which are
+// decoded at about four bytes a pixel. It allows an 8K screenshot.
+const maxImagePixelsDecoded = 40_000_000
+
+// decodeImage decodes a PNG, JPEG, or GIF after checking the size its
+// header declares, so a small file cannot claim a huge image.
+func decodeImage(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixelsDecoded {
+		return nil, fmt.Errorf("image of %dx%d is more than %d pixels", cfg.Width, cfg.Height, maxImagePixelsDecoded)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// analyzeImage reports the contrast of the text in each region. Without
+// regions the image is cut into tiles of the given size, and the tiles that
+// appear to hold text are reported.
+func analyzeImage(img image.Image, regions []ImageRegion, tile int) (ImageAnalysis, error) {
+	bounds := img.Bounds()
+	a := ImageAnalysis{Width: bounds.Dx(), Height: bounds.Dy(), Regions: []RegionResult{}}
+	for i, r := range regions {
+		switch r.Target {
+		case "", TargetSmallText, TargetLargeText:
+		default:
+			return a, fmt.Errorf("region %d: invalid target %q (must be smallText or largeText)", i+1, r.Target)
+		}
+		rect := r.rect().Add(bounds.Min)
+		if r.Width <= 0 || r.Height <= 0 || !rect.In(bounds) {
+			return a, fmt.Errorf("region %d (%d,%d %dx%d) is empty or outside the %dx%d image",
+				i+1, r.X, r.Y, r.Width, r.Height, a.Width, a.Height)
+		}
+		result, _, _ := analyzeRegion(img, r)
+		a.Regions = append(a.Regions, result)
+	}
+	if len(regions) > 0 {
+		return a, nil
+	}
+
+	if tile <= 0 {
+		tile = defaultTile
+	}
+	a.Tile = tile
+	for y := 0; y < a.Height; y += tile {
+		for x := 0; x < a.Width; x += tile {
+			r := ImageRegion{X: x, Y: y, Width: min(tile, a.Width-x), Height: min(tile, a.Height-y)}
+			result, fg, bg := analyzeRegion(img, r)
+			if result.ForegroundShare < minForegroundShare || fg.dist(bg) < minClusterDistance {
+				continue
+			}
+			a.Regions = append(a.Regions, result)
+		}
+	}
+	return a, nil
+}
+
+// renderHeatmap writes img as a PNG with failing regions tinted red and
+// regions that fail only for small text tinted orange. Passing regions
+// that were given explicitly are outlined in green.
+func renderHeatmap(w io.Writer, img image.Image, a ImageAnalysis) error {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Over)
+
+	for _, r := range a.Regions {
+		rect := r.rect()
+		hex := categoryColors[r.Category]
+		if r.Pass {
+			hex = categoryColors[CategoryAA]
+		}
+		c, err := hexRGBA(hex)
+		if err != nil {
+			return err
+		}
+		if !r.Pass {
+			tint := color.NRGBA{c.R, c.G, c.B, 0x66}
+			draw.Draw(out, rect, image.NewUniform(tint), image.Point{}, draw.Over)
+		}
+		if !r.Pass || a.Tile == 0 {
+			outline(out, rect, c)
+		}
+	}
+	return png.Encode(w, out)
+}
+
+// outline draws a 2px border just inside r.
+func outline(dst draw.Image, r image.Rectangle, c color.Color) {
+	u := image.NewUniform(c)
+	t := min(2, r.Dx(), r.Dy())
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t),
+		image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+t, r.Max.Y),
+		image.Rect(r.Max.X-t, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(dst, edge, u, image.Point{}, draw.Src)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// APCA (Accessible Perceptual Contrast Algorithm) constants, version
+// 0.0.98G-4g.
+const (
+	apcaMainTRC   = 2.4
+	apcaBlkThrs   = 0.022
+	apcaBlkClmp   = 1.414
+	apcaNormBG    = 0.56
+	apcaNormTXT   = 0.57
+	apcaRevTXT    = 0.62
+	apcaRevBG     = 0.65
+	apcaScale     = 1.14
+	apcaLoOffset  = 0.027
+	apcaDeltaYMin = 0.0005
+	apcaLoClip    = 0.1
+)
+
+// apcaY is APCA's screen luminance estimate, which uses a plain 2.4 power
+// curve instead of the piecewise sRGB transfer function.
+func apcaY(hex string) (float64, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("invalid hex: %s", hex)
+	}
+	var channels [3]float64
+	for i := range channels {
+		v, err := parseHex(hex[i*2 : i*2+2])
+		if err != nil {
+			return 0, err
+		}
+		channels[i] = math.Pow(float64(v)/255.0, apcaMainTRC)
+	}
+	y := 0.2126729*channels[0] + 0.7151522*channels[1] + 0.0721750*channels[2]
+	if y < apcaBlkThrs {
+		y += math.Pow(apcaBlkThrs-y, apcaBlkClmp)
+	}
+	return y, nil
+}
+
+// apcaContrast returns the lightness contrast Lc of text on background.
+// Unlike the WCAG ratio it depends on polarity: dark text on a light
+// background gives a positive value and light text on dark a negative one.
+func apcaContrast(textHex, bgHex string) (float64, error) {
+	yText, err := apcaY(textHex)
+	if err != nil {
+		return 0, err
+	}
+	yBg, err := apcaY(bgHex)
+	if err != nil {
+		return 0, err
+	}
+	if math.Abs(yBg-yText) < apcaDeltaYMin {
+		return 0, nil
+	}
+
+	var out float64
+	if yBg > yText {
+		sapc := (math.Pow(yBg, apcaNormBG) - math.Pow(yText, apcaNormTXT)) * apcaScale
+		if sapc >= apcaLoClip {
+			out = sapc - apcaLoOffset
+		}
+	} else {
+		sapc := (math.Pow(yBg, apcaRevBG) - math.Pow(yText, apcaRevTXT)) * apcaScale
+		if sapc <= -apcaLoClip {
+			out = sapc + apcaLoOffset
+		}
+	}
+	return out * 100, nil
+}
+
+// apcaRequiredLc is the minimum |Lc| for content of the given kind, size in
+// CSS pixels, and weight, following APCA's Bronze simple-mode levels:
+// Lc 90 for small body text, 75 for body text, 60 for other content text,
+// 45 for large or heavy headings, and 30 for solid icons and UI parts.
+func apcaRequiredLc(target Target, sizePx float64, weight int) float64 {
+	if target == TargetNonText {
+		return 30
+	}
+	bold := weight >= 700
+	switch {
+	case sizePx >= 36 || (sizePx >= 24 && bold):
+		return 45
+	case sizePx >= 24 || (sizePx >= 16 && bold):
+		return 60
+	case sizePx >= 18 || (sizePx >= 14 && bold):
+		return 75
+	default:
+		return 90
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+func writeJSON(w http.ResponseWriter, r *http.Request, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode JSON response", "error", err)
+	}
+}
+
+// APIError is the body of every JSON API error.
+type APIError struct {
+	// Error is the translated message and Detail says what went wrong.
+	Error  string `json:"error"`
+	Detail string `json:"detail"`
+}
+
+// apiError is the JSON counterpart of httpError.
+func apiError(w http.ResponseWriter, r *http.Request, key string, err error, code int) {
+	slog.ErrorContext(r.Context(), catalogs[defaultLocale].T(key), "error", err, "status", code)
+	writeJSON(w, r, code, APIError{Error: localizerFor(r).T(key), Detail: err.Error()})
+}
+
+// apiContrastsHandler serves the same page of results as the HTML view.
+func apiContrastsHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := requestResultQuery(r)
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+
+	colors, err := requestColors(r)
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, queryResults(r.Context(), colors, q))
+}
+
+// UsageReport is the verdict on every usage rule of a palette.
+type UsageReport struct {
+	Algorithm string           `json:"algorithm"`
+	Usages    []UsageResult    `json:"usages"`
+	Warnings  []PaletteWarning `json:"warnings"`
+}
+
+// apiUsagesHandler returns one verdict per usage rule in the palette.
+func apiUsagesHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := requestResultQuery(r)
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+
+	colors, err := requestColors(r)
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+
+	usages, warnings := evaluateUsages(r.Context(), colors, q.Algorithm)
+	if usages == nil {
+		usages = []UsageResult{}
+	}
+	if warnings == nil {
+		warnings = []PaletteWarning{}
+	}
+	writeJSON(w, r, http.StatusOK, UsageReport{q.Algorithm, usages, warnings})
+}
+
+// apiPalettesHandler lists the stored palettes on GET, and on POST
+// generates a palette from a PaletteSpec in the request body.
+func apiPalettesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		project, ok := storeProject(w, r)
+		if !ok {
+			return
+		}
+		palettes, err := store.List(r.Context(), project)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, palettes)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var spec PaletteSpec
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		apiError(w, r, "error.badPalette", err, http.StatusBadRequest)
+		return
+	}
+
+	palette, err := GeneratePalette(r.Context(), spec)
+	if errors.Is(err, errUnsatisfiable) {
+		apiError(w, r, "error.badPalette", err, http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		apiError(w, r, "error.badPalette", err, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, palette)
+}
+
+// maxImageUpload is the largest image accepted for analysis.
+const maxImageUpload = 20 << 20
+
+// parseImageUpload reads a multipart form with the image in "image", and
+// optionally a JSON array of regions in "regions" and a tile size in "tile".
+func parseImageUpload(w http.ResponseWriter, r *http.Request) (image.Image, []ImageRegion, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageUpload+1<<20)
+	if err := r.ParseMultipartForm(maxImageUpload); err != nil {
+		return nil, nil, 0, err
+	}
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer file.Close()
+	img, err := decodeImage(file)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var regions []ImageRegion
+	if s := r.FormValue("regions"); s != "" {
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&regions); err != nil {
+			return nil, nil, 0, fmt.Errorf("invalid regions: %w", err)
+		}
+	}
+	tile, err := parseBoundedInt(r.Form, "tile", defaultTile, 8, 512)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return img, regions, tile, nil
+}
+
+// apiImagesHandler analyzes an uploaded screenshot or mockup. /api/images
+// returns the regions as JSON and /api/images/heatmap.png the heatmap.
+func apiImagesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	img, regions, tile, err := parseImageUpload(w, r)
+	if err != nil {
+		apiError(w, r, "error.badImage", err, http.StatusBadRequest)
+		return
+	}
+	analysis, err := analyzeImage(img, regions, tile)
+	if err != nil {
+		apiError(w, r, "error.badImage", err, http.StatusBadRequest)
+		return
+	}
+
+	if !strings.HasSuffix(r.URL.Path, "/api/images/heatmap.png") {
+		writeJSON(w, r, http.StatusOK, analysis)
+		return
+	}
+	var buf bytes.Buffer
+	if err := renderHeatmap(&buf, img, analysis); err != nil {
+		apiError(w, r, "error.render", err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	buf.WriteTo(w)
+}
+
+// IconReport is the outcome of checking one SVG icon.
+type IconReport struct {
+	Findings []IconFinding `json:"findings"`
+	Warnings []string      `json:"warnings"`
+}
+
+// apiIconsHandler checks the SVG icon in the request body against the
+// backgrounds given by bg, or every dark palette color. currentColor names
+// the color the icon's currentColor stands for.
+func apiIconsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	colors, err := requestColors(r)
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+	v := r.URL.Query()
+	backgrounds, err := iconBackgrounds(colors, v["bg"])
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+	currentHex, err := iconCurrentColor(colors, v.Get("currentColor"))
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+
+	findings, warnings, err := checkIcon("icon.svg", http.MaxBytesReader(w, r.Body, 1<<20), currentHex, backgrounds)
+	if err != nil {
+		apiError(w, r, "error.badIcon", err, http.StatusBadRequest)
+		return
+	}
+	if findings == nil {
+		findings = []IconFinding{}
+	}
+	if warnings == nil {
+		warnings = []string{}
+	}
+	writeJSON(w, r, http.StatusOK, IconReport{findings, warnings})
+}
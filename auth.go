@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role is what a user may do. Each role includes the ones before it.
+type Role int
+
+const (
+	RoleNone Role = iota
+	// RoleViewer reads results and runs checks.
+	RoleViewer
+	// RoleEditor also changes palettes.
+	RoleEditor
+	// RoleAdmin also manages projects and webhooks.
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleEditor:
+		return "editor"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Role) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "viewer":
+		*r = RoleViewer
+	case "editor":
+		*r = RoleEditor
+	case "admin":
+		*r = RoleAdmin
+	case "none", "":
+		*r = RoleNone
+	default:
+		return fmt.Errorf("invalid role %q (must be viewer, editor, or admin)", text)
+	}
+	return nil
+}
+
+// Principal is who made a request.
+type Principal struct {
+	Name string
+	Role Role
+	// Method is how the principal was authenticated: token, basic, oidc,
+	// or anonymous.
+	Method string
+}
+
+// anonymous is the principal of requests without credentials when the
+// server runs without -auth, which keeps it open as before.
+var anonymous = &Principal{Name: "anonymous", Role: RoleAdmin, Method: "anonymous"}
+
+// An Authenticator recognizes one kind of credentials. It returns nil and
+// no error when a request does not carry its kind, and an error when it
+// carries credentials that are not valid.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+	// Challenge is the WWW-Authenticate value sent with 401 responses.
+	Challenge() string
+}
+
+var errBadCredentials = errors.New("invalid credentials")
+
+// AuthConfig is the file named by -auth.
+type AuthConfig struct {
+	// Anonymous is the role of requests without credentials, none unless
+	// given.
+	Anonymous Role          `json:"anonymous"`
+	Tokens    []TokenConfig `json:"tokens"`
+	Users     []UserConfig  `json:"users"`
+	OIDC      *OIDCConfig   `json:"oidc"`
+}
+
+// TokenConfig is a static API token, sent as "Authorization: Bearer". Only
+// its SHA-256 is stored.
+type TokenConfig struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Role   Role   `json:"role"`
+}
+
+// UserConfig is a user who signs in with HTTP basic authentication.
+type UserConfig struct {
+	Name   string `json:"name"`
+	Bcrypt string `json:"bcrypt"`
+	Role   Role   `json:"role"`
+}
+
+// auth holds the authenticators of the server, and is nil without -auth.
+var auth *authChain
+
+type authChain struct {
+	anonymous      Role
+	authenticators []Authenticator
+}
+
+// loadAuth reads an AuthConfig and builds its authenticators.
+func loadAuth(filename string) (*authChain, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var cfg AuthConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	chain := &authChain{anonymous: cfg.Anonymous}
+	if len(cfg.Tokens) > 0 {
+		tokens := tokenAuth{}
+		for _, t := range cfg.Tokens {
+			sum, err := hex.DecodeString(t.SHA256)
+			if err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("%s: token %q: sha256 must be 64 hex digits", filename, t.Name)
+			}
+			tokens = append(tokens, t)
+		}
+		chain.authenticators = append(chain.authenticators, tokens)
+	}
+	if len(cfg.Users) > 0 {
+		users := basicAuth{users: map[string]UserConfig{}}
+		for _, u := range cfg.Users {
+			if _, err := bcrypt.Cost([]byte(u.Bcrypt)); err != nil {
+				return nil, fmt.Errorf("%s: user %q: %w", filename, u.Name, err)
+			}
+			users.users[u.Name] = u
+		}
+		if users.dummy, err = bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost); err != nil {
+			return nil, err
+		}
+		chain.authenticators = append(chain.authenticators, users)
+	}
+	if cfg.OIDC != nil {
+		verifier, err := newOIDCVerifier(*cfg.OIDC)
+		if err != nil {
+			return nil, fmt.Errorf("%s: oidc: %w", filename, err)
+		}
+		chain.authenticators = append(chain.authenticators, verifier)
+	}
+	return chain, nil
+}
+
+// authenticate returns the principal of a request: the first authenticator
+// that recognizes its credentials decides.
+func (c *authChain) authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c.authenticators {
+		p, err := a.Authenticate(r)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	if r.Header.Get("Authorization") != "" {
+		return nil, errBadCredentials
+	}
+	return &Principal{Name: "anonymous", Role: c.anonymous, Method: "anonymous"}, nil
+}
+
+func (c *authChain) challenge(w http.ResponseWriter) {
+	var sent []string
+	for _, a := range c.authenticators {
+		if challenge := a.Challenge(); !slices.Contains(sent, challenge) {
+			w.Header().Add("WWW-Authenticate", challenge)
+			sent = append(sent, challenge)
+		}
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+type tokenAuth []TokenConfig
+
+func (tokens tokenAuth) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(token))
+	want := hex.EncodeToString(sum[:])
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(t.SHA256)), []byte(want)) == 1 {
+			return &Principal{Name: t.Name, Role: t.Role, Method: "token"}, nil
+		}
+	}
+	// The token may still be an OIDC token for a later authenticator.
+	return nil, nil
+}
+
+func (tokenAuth) Challenge() string { return `Bearer realm="contrast-checker"` }
+
+type basicAuth struct {
+	users map[string]UserConfig
+	// dummy is compared against for unknown names, so that they take as
+	// long as wrong passwords.
+	dummy []byte
+}
+
+func (b basicAuth) Authenticate(r *http.Request) (*Principal, error) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	u, known := b.users[name]
+	if !known {
+		bcrypt.CompareHashAndPassword(b.dummy, []byte(password))
+		return nil, errBadCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Bcrypt), []byte(password)); err != nil {
+		return nil, errBadCredentials
+	}
+	return &Principal{Name: u.Name, Role: u.Role, Method: "basic"}, nil
+}
+
+func (basicAuth) Challenge() string { return `Basic realm="contrast-checker", charset="UTF-8"` }
+
+// requestPrincipal returns who made a request.
+func requestPrincipal(r *http.Request) *Principal {
+	if p, ok := r.Context().Value(principalKey).(*Principal); ok {
+		return p
+	}
+	return anonymous
+}
+
+// canRequest reports whether the principal of r has at least role.
+func canRequest(r *http.Request, role Role) bool {
+	return requestPrincipal(r).Role >= role
+}
+
+// authorize authenticates each request and lets it through when the
+// principal has the role: read for GET and HEAD, write for other methods.
+// Without -auth every request is let through.
+func authorize(read, write Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if auth == nil {
+			next(w, r)
+			return
+		}
+		fail := httpError
+		if strings.Contains(r.URL.Path, "/api/") {
+			fail = apiError
+		}
+
+		p, err := auth.authenticate(r)
+		if err != nil {
+			auth.challenge(w)
+			fail(w, r, "error.unauthorized", err, http.StatusUnauthorized)
+			return
+		}
+		need := write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			need = read
+		}
+		if p.Role < need {
+			if p.Method == "anonymous" {
+				auth.challenge(w)
+				fail(w, r, "error.unauthorized", errors.New("sign in to continue"), http.StatusUnauthorized)
+				return
+			}
+			fail(w, r, "error.forbidden", fmt.Errorf("%s is a %s; %s needs %s", p.Name, p.Role, r.Method, need), http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey, p)))
+	}
+}
+
+// runPasswd prints the bcrypt hash of a password read from stdin, for the
+// users of an -auth file.
+func runPasswd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("passwd", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cost := fs.Int("cost", bcrypt.DefaultCost, "bcrypt cost")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: passwd [flags] < password")
+		fmt.Fprintln(stderr, "example: echo -n hunter2 | passwd")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		fmt.Fprintln(stderr, "empty password")
+		return 2
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), *cost)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, string(hash))
+	return 0
+}
+
+// runToken generates an API token and prints it with the entry that goes
+// in the tokens of an -auth file.
+func runToken(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", "ci", "name recorded as the author of changes")
+	role := RoleViewer
+	fs.TextVar(&role, "role", RoleViewer, "role of the token (viewer, editor, or admin)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: token [flags]")
+		fmt.Fprintln(stderr, "example: token -name deploy -role editor")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	token := hex.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(token))
+	entry, err := json.MarshalIndent(TokenConfig{Name: *name, SHA256: hex.EncodeToString(sum[:]), Role: role}, "", "  ")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "token: %s\n%s\n", token, entry)
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthorize(t *testing.T) {
+	if err := loadWeb(""); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("editor-token"))
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := auth
+	t.Cleanup(func() { auth = saved })
+	auth = &authChain{
+		anonymous: RoleViewer,
+		authenticators: []Authenticator{
+			tokenAuth{{Name: "ci", SHA256: hex.EncodeToString(sum[:]), Role: RoleEditor}},
+			basicAuth{users: map[string]UserConfig{"ana": {Name: "ana", Bcrypt: string(hash), Role: RoleAdmin}}, dummy: hash},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		bearer    string
+		user      string
+		password  string
+		write     Role
+		want      int
+		challenge bool
+	}{
+		{name: "anonymous read", method: http.MethodGet, path: "/", write: RoleEditor, want: http.StatusOK},
+		{name: "anonymous write", method: http.MethodPost, path: "/api/palettes", write: RoleEditor, want: http.StatusUnauthorized, challenge: true},
+		{name: "token write", method: http.MethodPut, path: "/api/palettes/a", bearer: "editor-token", write: RoleEditor, want: http.StatusOK},
+		{name: "token without the role", method: http.MethodPost, path: "/api/webhooks", bearer: "editor-token", write: RoleAdmin, want: http.StatusForbidden},
+		{name: "token read of an admin route", method: http.MethodGet, path: "/api/webhooks", bearer: "editor-token", write: RoleAdmin, want: http.StatusOK},
+		{name: "unknown token", method: http.MethodGet, path: "/api/contrasts", bearer: "nope", write: RoleEditor, want: http.StatusUnauthorized, challenge: true},
+		{name: "basic", method: http.MethodPost, path: "/api/webhooks", user: "ana", password: "hunter2", write: RoleAdmin, want: http.StatusOK},
+		{name: "basic with a wrong password", method: http.MethodGet, path: "/", user: "ana", password: "wrong", write: RoleEditor, want: http.StatusUnauthorized, challenge: true},
+		{name: "basic with an unknown user", method: http.MethodGet, path: "/", user: "bob", password: "hunter2", write: RoleEditor, want: http.StatusUnauthorized, challenge: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Principal
+			h := authorize(RoleViewer, tt.write, func(w http.ResponseWriter, r *http.Request) {
+				got = requestPrincipal(r)
+			})
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.user != "" {
+				r.SetBasicAuth(tt.user, tt.password)
+			}
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if challenged := w.Header().Get("WWW-Authenticate") != ""; challenged != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want one: %v", w.Header().Get("WWW-Authenticate"), tt.challenge)
+			}
+			if (got != nil) != (tt.want == http.StatusOK) {
+				t.Errorf("handler called = %v, want %v", got != nil, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,784 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// runQuery implements "query [flags] [expression]", which prints the palette
+// results matching a search expression without starting the server.
+func runQuery(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	colorsFile := fs.String("colors", "colors.json", "palette file to read")
+	format := fs.String("format", "text", "output format (text, json, or csv)")
+	sortBy := fs.String("sort", "", "sort by foreground, background, ratio, or level")
+	order := fs.String("order", "", "sort order (asc or desc)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: query [flags] [expression]")
+		fmt.Fprintln(stderr, "example: query -sort ratio 'fg:red* ratio<4.5 large:AA'")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	v := url.Values{}
+	v.Set("q", strings.Join(fs.Args(), " "))
+	v.Set("sort", *sortBy)
+	v.Set("order", *order)
+	v.Set("limit", strconv.Itoa(maxPageLimit))
+	q, err := parseResultQuery(v)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	colors, err := LoadColors(*colorsFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	// The command prints every match rather than a single page.
+	page := allResults(context.Background(), colors, q)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(page.Results); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "csv":
+		writer := csv.NewWriter(stdout)
+		writer.Write(csvHeader(catalogs[defaultLocale]))
+		writeResultsToCSV(writer, page.Results)
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "text":
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FOREGROUND\tBACKGROUND\tRATIO\tSMALL\tLARGE\tNON-TEXT")
+		for _, r := range page.Results {
+			fmt.Fprintf(tw, "%s (%s)\t%s (%s)\t%.2f\t%s\t%s\t%s\n",
+				r.ForegroundName, r.ForegroundHex, r.BackgroundName, r.BackgroundHex,
+				r.ContrastRatio, r.LevelSmallText, r.LevelLargeText, r.LevelNonText)
+		}
+		tw.Flush()
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+	return 0
+}
+
+// requirementFlags collects repeated -require flags of the form
+// theme:step:level[:target].
+type requirementFlags []string
+
+func (f *requirementFlags) String() string     { return strings.Join(*f, ",") }
+func (f *requirementFlags) Set(v string) error { *f = append(*f, v); return nil }
+
+// runGenerate implements "generate [flags]", which prints a colors.json
+// palette generated from a seed color.
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	specFile := fs.String("spec", "", "JSON palette spec to read instead of the flags below")
+	seed := fs.String("seed", "", "seed color (#rrggbb)")
+	name := fs.String("name", "brand", "prefix of the generated color names")
+	steps := fs.String("steps", "", "comma-separated steps (default 50,100,200,...,900,950)")
+	lightBg := fs.String("light-bg", "#ffffff", "light theme background; empty to skip the theme")
+	darkBg := fs.String("dark-bg", "#121212", "dark theme background; empty to skip the theme")
+	reportFile := fs.String("report", "", "also write the contrast report of the palette to this CSV file")
+	var requires requirementFlags
+	fs.Var(&requires, "require", "theme:step:level[:target], e.g. light:600:AA (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: generate [flags]")
+		fmt.Fprintln(stderr, "example: generate -seed '#3366ff' -require light:600:AA -require dark:400:AAA:largeText > colors.json")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var spec PaletteSpec
+	if *specFile != "" {
+		data, err := os.ReadFile(*specFile)
+		if err == nil {
+			err = json.Unmarshal(data, &spec)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	} else {
+		spec.Seed = *seed
+		spec.Name = *name
+		if *steps != "" {
+			for _, s := range strings.Split(*steps, ",") {
+				step, err := strconv.Atoi(strings.TrimSpace(s))
+				if err != nil {
+					fmt.Fprintf(stderr, "invalid step %q\n", s)
+					return 2
+				}
+				spec.Steps = append(spec.Steps, step)
+			}
+		}
+		themes := map[string]*ThemeSpec{}
+		for _, t := range []ThemeSpec{{Theme: "light", Background: *lightBg}, {Theme: "dark", Background: *darkBg}} {
+			if t.Background != "" {
+				spec.Themes = append(spec.Themes, t)
+			}
+		}
+		for i := range spec.Themes {
+			themes[spec.Themes[i].Theme] = &spec.Themes[i]
+		}
+		for _, r := range requires {
+			parts := strings.Split(r, ":")
+			if len(parts) < 3 || len(parts) > 4 {
+				fmt.Fprintf(stderr, "invalid requirement %q: want theme:step:level[:target]\n", r)
+				return 2
+			}
+			theme, ok := themes[parts[0]]
+			if !ok {
+				fmt.Fprintf(stderr, "invalid requirement %q: no %q theme\n", r, parts[0])
+				return 2
+			}
+			step, err := strconv.Atoi(parts[1])
+			if err != nil {
+				fmt.Fprintf(stderr, "invalid requirement %q: bad step\n", r)
+				return 2
+			}
+			level, err := ParseLevel(parts[2])
+			if err != nil {
+				fmt.Fprintf(stderr, "invalid requirement %q: %v\n", r, err)
+				return 2
+			}
+			req := StepRequirement{Step: step, Level: level}
+			if len(parts) == 4 {
+				req.Target = Target(parts[3])
+			}
+			theme.Requirements = append(theme.Requirements, req)
+		}
+	}
+
+	palette, err := GeneratePalette(context.Background(), spec)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(palette.Palette); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	for _, r := range palette.Requirements {
+		fmt.Fprintf(stderr, "%s %s: %s on %s is %.2f, %s for %s\n",
+			r.Theme, r.Name, r.Hex, r.Background, r.Ratio, r.Level, r.Target)
+	}
+
+	if *reportFile != "" {
+		file, err := os.Create(*reportFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+		writer := csv.NewWriter(file)
+		writer.Write(csvHeader(catalogs[defaultLocale]))
+		writeResultsToCSV(writer, palette.Results)
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// runSwatch implements "swatch [flags] [expression]", which renders one
+// pair, or every pair matching the expression, as an SVG or PNG image.
+func runSwatch(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("swatch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	colorsFile := fs.String("colors", "colors.json", "palette file to read")
+	fg := fs.String("fg", "", "foreground name or #rrggbb; with -bg renders a single pair")
+	bg := fs.String("bg", "", "background name or #rrggbb")
+	format := fs.String("format", "", "image format (svg or png); defaults to the -o extension, else svg")
+	output := fs.String("o", "", "file to write instead of standard output")
+	v := url.Values{}
+	for _, opt := range []struct{ name, usage string }{
+		{"font", "font: regular, bold, or mono (default regular)"},
+		{"small", "small sample size in px (default 16)"},
+		{"large", "large sample size in px (default 24)"},
+		{"width", "card width in px (default 360)"},
+		{"columns", "cards per row (default 3)"},
+		{"text", "sample text"},
+	} {
+		name := opt.name
+		fs.Func(name, opt.usage, func(s string) error { v.Set(name, s); return nil })
+	}
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: swatch [flags] [expression]")
+		fmt.Fprintln(stderr, "example: swatch -fg navy -bg white -o navy-on-white.png")
+		fmt.Fprintln(stderr, "example: swatch -columns 4 'requiresFix:true' > failing.svg")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	opts, err := parseSwatchOptions(v)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *format == "" {
+		*format = "svg"
+		if strings.HasSuffix(*output, ".png") {
+			*format = "png"
+		}
+	}
+	if _, ok := imageContentTypes[*format]; !ok {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	colors, err := LoadColors(*colorsFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var results []ContrastResult
+	if *fg != "" || *bg != "" {
+		pair, err := pairResult(colors, *fg, *bg)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		results = []ContrastResult{pair}
+	} else {
+		qv := url.Values{}
+		qv.Set("q", strings.Join(fs.Args(), " "))
+		q, err := parseResultQuery(qv)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		results = allResults(context.Background(), colors, q).Results
+	}
+
+	out := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := renderSwatches(out, *format, results, opts); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// listFlags collects a repeated flag such as -css.
+type listFlags []string
+
+func (f *listFlags) String() string     { return strings.Join(*f, ",") }
+func (f *listFlags) Set(v string) error { *f = append(*f, v); return nil }
+
+// findFiles lists the files named in roots and the files under the
+// directories among them whose extension is one of exts.
+func findFiles(roots []string, exts ...string) ([]string, error) {
+	var files []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if path == root || slices.Contains(exts, strings.ToLower(filepath.Ext(path))) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// runScan implements "scan [flags] path...", which checks the text of local
+// HTML pages against the backgrounds their CSS puts behind it. Directories
+// are searched for .html and .htm files. It exits with status 1 when any
+// text fails AA.
+func runScan(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text, json, or csv)")
+	all := fs.Bool("all", false, "report passing text too")
+	var sheets listFlags
+	fs.Var(&sheets, "css", "stylesheet to apply before each page's own (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: scan [flags] path...")
+		fmt.Fprintln(stderr, "example: scan -css site.css public/")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	switch *format {
+	case "text", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	pages, err := findFiles(fs.Args(), ".html", ".htm")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	cache := stylesheetCache{}
+	findings := []ScanFinding{}
+	failed := false
+	for _, page := range pages {
+		found, warnings, err := scanHTMLFile(page, sheets, cache)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(stderr, "warning:", w)
+		}
+		for _, f := range found {
+			failed = failed || !f.Pass
+			if *all || !f.Pass {
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "csv":
+		writer := csv.NewWriter(stdout)
+		writer.Write([]string{"File", "Line", "Selector", "Text", "Foreground", "Background", "Contrast Ratio",
+			"Contrast Ratio (unrounded)", "Font Size (px)", "Font Weight", "Target", "Level", "Pass",
+			"Color Source", "Background Source"})
+		for _, f := range findings {
+			writer.Write([]string{f.File, strconv.Itoa(f.Line), f.Selector, f.Text, f.ForegroundHex, f.BackgroundHex,
+				fmt.Sprintf("%.2f", f.ContrastRatio), strconv.FormatFloat(f.ContrastRatioRaw, 'f', -1, 64),
+				strconv.FormatFloat(f.FontSizePx, 'f', -1, 64), strconv.Itoa(f.FontWeight), string(f.Target),
+				f.Level.String(), strconv.FormatBool(f.Pass), f.ColorSource, f.BackgroundSource})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	default:
+		for _, f := range findings {
+			fmt.Fprintf(stdout, "%s:%d: %s: %q %s on %s is %.2f:1, %s for %s (%gpx, weight %d)\n",
+				f.File, f.Line, f.Selector, f.Text, f.ForegroundHex, f.BackgroundHex,
+				f.ContrastRatio, f.Level, f.Target, f.FontSizePx, f.FontWeight)
+			fmt.Fprintf(stdout, "\tcolor from %s, background from %s\n", f.ColorSource, f.BackgroundSource)
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// regionFlags collects repeated -region flags of the form x,y,w,h[,label].
+type regionFlags []ImageRegion
+
+func (f *regionFlags) String() string { return fmt.Sprint(len(*f), " regions") }
+
+func (f *regionFlags) Set(v string) error {
+	parts := strings.SplitN(v, ",", 5)
+	if len(parts) < 4 {
+		return fmt.Errorf("invalid region %q: want x,y,w,h[,label]", v)
+	}
+	var n [4]int
+	for i := range n {
+		var err error
+		if n[i], err = strconv.Atoi(strings.TrimSpace(parts[i])); err != nil {
+			return fmt.Errorf("invalid region %q: want x,y,w,h[,label]", v)
+		}
+	}
+	r := ImageRegion{X: n[0], Y: n[1], Width: n[2], Height: n[3]}
+	if len(parts) == 5 {
+		r.Label = parts[4]
+	}
+	*f = append(*f, r)
+	return nil
+}
+
+// runAnalyze implements "analyze [flags] image", which estimates the text
+// and background colors of a PNG, JPEG, or GIF screenshot or mockup. It
+// exits with status 1 when any region fails AA.
+func runAnalyze(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	regionsFile := fs.String("regions", "", "JSON file with an array of regions ({label, x, y, width, height, target})")
+	tile := fs.Int("tile", defaultTile, "tile size in px when no regions are given")
+	heatmap := fs.String("heatmap", "", "also write a PNG marking low-contrast regions to this file")
+	format := fs.String("format", "text", "output format (text, json, or csv)")
+	all := fs.Bool("all", false, "report passing regions too")
+	var regions regionFlags
+	fs.Var(&regions, "region", "text bounding box as x,y,w,h[,label] (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: analyze [flags] image")
+		fmt.Fprintln(stderr, "example: analyze -region 40,120,300,24,headline -heatmap heatmap.png mockup.png")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *tile < 8 || *tile > 512 {
+		fmt.Fprintf(stderr, "invalid tile %d (must be 8-512)\n", *tile)
+		return 2
+	}
+	switch *format {
+	case "text", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+	if *regionsFile != "" {
+		data, err := os.ReadFile(*regionsFile)
+		var fromFile []ImageRegion
+		if err == nil {
+			err = json.Unmarshal(data, &fromFile)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		regions = append(fromFile, regions...)
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	img, err := decodeImage(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	analysis, err := analyzeImage(img, regions, *tile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if *heatmap != "" {
+		out, err := os.Create(*heatmap)
+		if err == nil {
+			err = renderHeatmap(out, img, analysis)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	failed := false
+	reported := []RegionResult{}
+	for _, r := range analysis.Regions {
+		failed = failed || !r.Pass
+		if *all || !r.Pass {
+			reported = append(reported, r)
+		}
+	}
+
+	switch *format {
+	case "json":
+		analysis.Regions = reported
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(analysis); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "csv":
+		writer := csv.NewWriter(stdout)
+		writer.Write([]string{"Label", "X", "Y", "Width", "Height", "Foreground", "Background", "Foreground Share",
+			"Contrast Ratio", "Contrast Ratio (unrounded)", "Target", "Small Text", "Large Text", "Pass"})
+		for _, r := range reported {
+			writer.Write([]string{r.Label, strconv.Itoa(r.X), strconv.Itoa(r.Y), strconv.Itoa(r.Width), strconv.Itoa(r.Height),
+				r.ForegroundHex, r.BackgroundHex, strconv.FormatFloat(r.ForegroundShare, 'f', -1, 64),
+				fmt.Sprintf("%.2f", r.ContrastRatio), strconv.FormatFloat(r.ContrastRatioRaw, 'f', -1, 64),
+				string(r.Target), r.LevelSmallText.String(), r.LevelLargeText.String(), strconv.FormatBool(r.Pass)})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	default:
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "REGION\tBOX\tFOREGROUND\tBACKGROUND\tRATIO\tSMALL\tLARGE\tPASS")
+		for _, r := range reported {
+			fmt.Fprintf(tw, "%s\t%d,%d %dx%d\t%s\t%s\t%.2f\t%s\t%s\t%t\n",
+				r.Label, r.X, r.Y, r.Width, r.Height, r.ForegroundHex, r.BackgroundHex,
+				r.ContrastRatio, r.LevelSmallText, r.LevelLargeText, r.Pass)
+		}
+		tw.Flush()
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// runIcons implements "icons [flags] path...", which checks the fill and
+// stroke colors of SVG icons against palette backgrounds. Directories are
+// searched for .svg files. It exits with status 1 when any color fails.
+func runIcons(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("icons", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	colorsFile := fs.String("colors", "colors.json", "palette file to read")
+	current := fs.String("current-color", "", "light palette name or #rrggbb that currentColor stands for")
+	format := fs.String("format", "text", "output format (text, json, or csv)")
+	all := fs.Bool("all", false, "report passing colors too")
+	var bgRefs listFlags
+	fs.Var(&bgRefs, "bg", "dark palette name or #rrggbb to check against (repeatable; default every dark color)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: icons [flags] path...")
+		fmt.Fprintln(stderr, "example: icons -current-color navy -bg white -bg black icons/")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	switch *format {
+	case "text", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	colors, err := LoadColors(*colorsFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	backgrounds, err := iconBackgrounds(colors, bgRefs)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	currentHex, err := iconCurrentColor(colors, *current)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	icons, err := findFiles(fs.Args(), ".svg")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	findings := []IconFinding{}
+	failed := false
+	for _, icon := range icons {
+		file, err := os.Open(icon)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		found, warnings, err := checkIcon(icon, file, currentHex, backgrounds)
+		file.Close()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(stderr, "warning:", w)
+		}
+		for _, f := range found {
+			failed = failed || !f.Pass
+			if *all || !f.Pass {
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "csv":
+		writer := csv.NewWriter(stdout)
+		writer.Write([]string{"File", "Line", "Element", "Property", "Value", "Color", "Background Name",
+			"Background Hex", "Contrast Ratio", "Contrast Ratio (unrounded)", "Level", "Pass"})
+		for _, f := range findings {
+			writer.Write([]string{f.File, strconv.Itoa(f.Line), f.Element, f.Property, f.Value, f.ColorHex,
+				f.BackgroundName, f.BackgroundHex, fmt.Sprintf("%.2f", f.ContrastRatio),
+				strconv.FormatFloat(f.ContrastRatioRaw, 'f', -1, 64), f.Level.String(), strconv.FormatBool(f.Pass)})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	default:
+		for _, f := range findings {
+			value := ""
+			if !strings.EqualFold(f.Value, f.ColorHex) {
+				value = " (" + f.Value + ")"
+			}
+			fmt.Fprintf(stdout, "%s:%d: %s %s %s%s on %s (%s) is %.2f:1, %s\n",
+				f.File, f.Line, f.Element, f.Property, f.ColorHex, value,
+				f.BackgroundName, f.BackgroundHex, f.ContrastRatio, f.Level)
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// runSurfaces implements "surfaces [flags] foreground", which checks one
+// light palette color on several backgrounds and reports the limiting
+// surface. It exits with status 1 when the foreground needs a fix on any
+// of them.
+func runSurfaces(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("surfaces", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	colorsFile := fs.String("colors", "colors.json", "palette file to read")
+	group := fs.String("group", "", "dark palette names to check against, with * and ? wildcards")
+	format := fs.String("format", "text", "output format (text, json, or csv)")
+	var bgRefs listFlags
+	fs.Var(&bgRefs, "bg", "dark palette name or #rrggbb to check against (repeatable; default every dark color)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: surfaces [flags] foreground")
+		fmt.Fprintln(stderr, "example: surfaces -group 'surface-*' -bg '#ffffff' black")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	switch *format {
+	case "text", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	colors, err := LoadColors(*colorsFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	backgrounds, err := surfaceBackgrounds(colors, bgRefs, *group)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	report, err := checkSurfaces(colors, fs.Arg(0), backgrounds)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	case "csv":
+		writer := csv.NewWriter(stdout)
+		writer.Write([]string{"Foreground Name", "Foreground Hex", "Background Name", "Background Hex",
+			"Contrast Ratio", "Contrast Ratio (unrounded)", "Category", "Limiting"})
+		for _, s := range report.Surfaces {
+			writer.Write([]string{s.ForegroundName, s.ForegroundHex, s.BackgroundName, s.BackgroundHex,
+				fmt.Sprintf("%.2f", s.ContrastRatio), strconv.FormatFloat(s.ContrastRatioRaw, 'f', -1, 64),
+				s.Category.String(), strconv.FormatBool(s.Limiting)})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	default:
+		for _, s := range report.Surfaces {
+			limiting := ""
+			if s.Limiting {
+				limiting = " (limiting)"
+			}
+			fmt.Fprintf(stdout, "%s (%s) on %s (%s) is %.2f:1, %s%s\n",
+				s.ForegroundName, s.ForegroundHex, s.BackgroundName, s.BackgroundHex, s.ContrastRatio, s.Category, limiting)
+		}
+		fmt.Fprintf(stdout, "%d surfaces: min %.2f:1 on %s, max %.2f:1, worst case %s\n",
+			len(report.Surfaces), report.MinRatio, report.Limiting, report.MaxRatio, report.Category)
+	}
+	for _, s := range report.Surfaces {
+		if s.RequiresFix {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+// Package client is a typed Go client for the contrast checker's JSON API.
+//
+// The types and methods in client_gen.go are generated from the server's
+// OpenAPI document; run go generate in this directory after changing the
+// API.
+//
+//	c := client.New("http://localhost:8080", client.WithToken(token))
+//	page, err := c.Project("shop").ListContrasts(ctx, &client.ListContrastsParams{Filter: "Fail"})
+package client
+
+//go:generate go run .. openapi -o openapi.json
+//go:generate go run ./internal/clientgen -o client_gen.go openapi.json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Client calls the API of one server, for the default project or the one
+// chosen with Project.
+type Client struct {
+	baseURL    string
+	project    string
+	httpClient *http.Client
+	authorize  func(*http.Request)
+}
+
+// An Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests with hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken signs requests in with an API token or an OIDC ID token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+}
+
+// WithBasicAuth signs requests in with a user name and password.
+func WithBasicAuth(user, password string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) { r.SetBasicAuth(user, password) }
+	}
+}
+
+// New returns a client of the server at baseURL, such as
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Project returns a client of the project with the given ID. Projects
+// themselves are managed through any client.
+func (c *Client) Project(id string) *Client {
+	p := *c
+	p.project = id
+	if id == "default" {
+		p.project = ""
+	}
+	return &p
+}
+
+// ETag returns the If-Match value of a palette version.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ResponseError is an error answered by the server.
+type ResponseError struct {
+	StatusCode int
+	// Message is the translated message and Detail says what went wrong.
+	Message string
+	Detail  string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("%d %s: %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message, e.Detail)
+}
+
+// request is one call made by a generated method.
+type request struct {
+	method, path string
+	query        url.Values
+	header       http.Header
+	body         io.Reader
+	contentType  string
+	// rootOnly requests are not sent to the project.
+	rootOnly bool
+	// ok are the statuses whose body is the result.
+	ok []int
+}
+
+// jsonBody encodes v as a request body.
+func jsonBody(v any) (io.Reader, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// do sends req and decodes the result into out: a pointer to a JSON value,
+// a *[]byte for any other body, or nil for none.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	u := c.baseURL
+	if c.project != "" && !req.rootOnly {
+		u += "/projects/" + url.PathEscape(c.project)
+	}
+	u += req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, req.method, u, req.body)
+	if err != nil {
+		return err
+	}
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	r.Header.Set("Accept", "application/json")
+	if c.authorize != nil {
+		c.authorize(r)
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if !slices.Contains(req.ok, resp.StatusCode) {
+		e := &ResponseError{StatusCode: resp.StatusCode}
+		var body APIError
+		if json.NewDecoder(resp.Body).Decode(&body) == nil {
+			e.Message, e.Detail = body.Error, body.Detail
+		} else {
+			e.Message = resp.Status
+		}
+		return e
+	}
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*out, err = io.ReadAll(resp.Body)
+		return err
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
@@ -0,0 +1,1275 @@
+// Code generated by clientgen from openapi.json. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type APCACheck struct {
+	Pass     bool    `json:"pass"`
+	Required float64 `json:"required"`
+	Use      string  `json:"use"`
+}
+
+type APCAResult struct {
+	Checks []APCACheck `json:"checks"`
+	Lc     float64     `json:"lc"`
+	LcRaw  float64     `json:"lcRaw"`
+}
+
+type APIError struct {
+	Detail string `json:"detail"`
+	Error  string `json:"error"`
+}
+
+type CVDSimulation struct {
+	BackgroundHex    string   `json:"backgroundHex"`
+	Category         Category `json:"category"`
+	ContrastRatio    float64  `json:"contrastRatio"`
+	ContrastRatioRaw float64  `json:"contrastRatioRaw"`
+	ForegroundHex    string   `json:"foregroundHex"`
+	Type             string   `json:"type"`
+}
+
+type Category string
+
+const (
+	CategoryAAA     Category = "AAA"
+	CategoryAA      Category = "AA"
+	CategoryAALarge Category = "AALarge"
+	CategoryFail    Category = "Fail"
+)
+
+type ColorSets struct {
+	Dark   map[string]string `json:"dark"`
+	Light  map[string]string `json:"light"`
+	Usages []UsageRule       `json:"usages,omitempty"`
+}
+
+type ColorValue struct {
+	Hex string `json:"hex"`
+}
+
+type ContrastResult struct {
+	BackgroundHex    string            `json:"backgroundHex"`
+	BackgroundName   string            `json:"backgroundName"`
+	BackgroundTheme  string            `json:"backgroundTheme"`
+	Category         Category          `json:"category"`
+	ContrastRatio    float64           `json:"contrastRatio"`
+	ContrastRatioRaw float64           `json:"contrastRatioRaw"`
+	Criteria         []CriterionResult `json:"criteria"`
+	ForegroundHex    string            `json:"foregroundHex"`
+	ForegroundName   string            `json:"foregroundName"`
+	ForegroundTheme  string            `json:"foregroundTheme"`
+	LevelLargeText   Level             `json:"levelLargeText"`
+	LevelNonText     Level             `json:"levelNonText"`
+	LevelSmallText   Level             `json:"levelSmallText"`
+	RequiresFix      bool              `json:"requiresFix"`
+}
+
+type CriterionResult struct {
+	Checks []ThresholdCheck `json:"checks"`
+	ID     string           `json:"id"`
+	Level  Level            `json:"level"`
+	Name   string           `json:"name"`
+}
+
+type GeneratedPalette struct {
+	Palette      ColorSets           `json:"palette"`
+	Requirements []RequirementResult `json:"requirements"`
+	Results      []ContrastResult    `json:"results"`
+	Usages       []UsageResult       `json:"usages"`
+}
+
+type IconFinding struct {
+	BackgroundHex    string  `json:"backgroundHex"`
+	BackgroundName   string  `json:"backgroundName"`
+	ColorHex         string  `json:"colorHex"`
+	ContrastRatio    float64 `json:"contrastRatio"`
+	ContrastRatioRaw float64 `json:"contrastRatioRaw"`
+	Element          string  `json:"element"`
+	File             string  `json:"file"`
+	Level            Level   `json:"level"`
+	Line             int     `json:"line"`
+	Pass             bool    `json:"pass"`
+	Property         string  `json:"property"`
+	Value            string  `json:"value"`
+}
+
+type IconReport struct {
+	Findings []IconFinding `json:"findings"`
+	Warnings []string      `json:"warnings"`
+}
+
+type ImageAnalysis struct {
+	Height  int            `json:"height"`
+	Regions []RegionResult `json:"regions"`
+	Tile    int            `json:"tile,omitempty"`
+	Width   int            `json:"width"`
+}
+
+type ImageRegion struct {
+	Height int    `json:"height"`
+	Label  string `json:"label,omitempty"`
+	Target Target `json:"target,omitempty"`
+	Width  int    `json:"width"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+}
+
+type ImageUpload struct {
+	Image   io.Reader     `json:"-"`
+	Regions []ImageRegion `json:"regions,omitempty"`
+	Tile    int           `json:"tile,omitempty"`
+}
+
+type Level string
+
+const (
+	LevelAAA  Level = "AAA"
+	LevelAA   Level = "AA"
+	LevelFail Level = "Fail"
+)
+
+type LevelCounts struct {
+	AA      int `json:"AA"`
+	AAA     int `json:"AAA"`
+	AALarge int `json:"AALarge"`
+	Fail    int `json:"Fail"`
+}
+
+type PairReport struct {
+	Apca             APCAResult        `json:"apca"`
+	BackgroundHex    string            `json:"backgroundHex"`
+	BackgroundName   string            `json:"backgroundName"`
+	BackgroundTheme  string            `json:"backgroundTheme"`
+	Category         Category          `json:"category"`
+	ContrastRatio    float64           `json:"contrastRatio"`
+	ContrastRatioRaw float64           `json:"contrastRatioRaw"`
+	Criteria         []CriterionResult `json:"criteria"`
+	ForegroundHex    string            `json:"foregroundHex"`
+	ForegroundName   string            `json:"foregroundName"`
+	ForegroundTheme  string            `json:"foregroundTheme"`
+	LevelLargeText   Level             `json:"levelLargeText"`
+	LevelNonText     Level             `json:"levelNonText"`
+	LevelSmallText   Level             `json:"levelSmallText"`
+	RequiresFix      bool              `json:"requiresFix"`
+	Simulations      []CVDSimulation   `json:"simulations"`
+	Suggestions      []Suggestion      `json:"suggestions"`
+}
+
+type PaletteColor struct {
+	Hex   string `json:"hex"`
+	Name  string `json:"name"`
+	Theme string `json:"theme"`
+}
+
+type PaletteSpec struct {
+	Name   string      `json:"name,omitempty"`
+	Seed   string      `json:"seed"`
+	Steps  []int       `json:"steps,omitempty"`
+	Themes []ThemeSpec `json:"themes,omitempty"`
+}
+
+type PaletteVersion struct {
+	Author    string      `json:"author"`
+	Colors    *ColorSets  `json:"colors,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Deleted   bool        `json:"deleted,omitempty"`
+	Message   string      `json:"message"`
+	Palette   string      `json:"palette"`
+	Project   string      `json:"project"`
+	Summary   LevelCounts `json:"summary"`
+	Version   int         `json:"version"`
+}
+
+type PaletteWarning struct {
+	Message string `json:"message"`
+	Name    string `json:"name"`
+	Theme   string `json:"theme"`
+	Value   string `json:"value"`
+}
+
+type Project struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Settings  ProjectSettings `json:"settings"`
+}
+
+type ProjectCheck struct {
+	Algorithm     string           `json:"algorithm"`
+	FailingUsages []UsageResult    `json:"failingUsages"`
+	Palette       string           `json:"palette"`
+	Pass          bool             `json:"pass"`
+	Problems      []string         `json:"problems"`
+	Project       string           `json:"project"`
+	Settings      ProjectSettings  `json:"settings"`
+	Totals        LevelCounts      `json:"totals"`
+	UsageWarnings []PaletteWarning `json:"usageWarnings"`
+	Version       int              `json:"version"`
+	Warnings      []PaletteWarning `json:"warnings"`
+}
+
+type ProjectExport struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Palettes  []StoredPalette `json:"palettes"`
+	Settings  ProjectSettings `json:"settings"`
+}
+
+type ProjectSettings struct {
+	Algorithm        string `json:"algorithm,omitempty"`
+	AllowWarnings    bool   `json:"allowWarnings,omitempty"`
+	MaxFailPairs     int    `json:"maxFailPairs,omitempty"`
+	MaxFailingUsages int    `json:"maxFailingUsages"`
+	Palette          string `json:"palette,omitempty"`
+}
+
+type ProjectUpdate struct {
+	Name     string          `json:"name"`
+	Settings ProjectSettings `json:"settings"`
+}
+
+type RegionResult struct {
+	BackgroundHex    string            `json:"backgroundHex"`
+	BackgroundName   string            `json:"backgroundName"`
+	BackgroundTheme  string            `json:"backgroundTheme"`
+	Category         Category          `json:"category"`
+	ContrastRatio    float64           `json:"contrastRatio"`
+	ContrastRatioRaw float64           `json:"contrastRatioRaw"`
+	Criteria         []CriterionResult `json:"criteria"`
+	ForegroundHex    string            `json:"foregroundHex"`
+	ForegroundName   string            `json:"foregroundName"`
+	ForegroundShare  float64           `json:"foregroundShare"`
+	ForegroundTheme  string            `json:"foregroundTheme"`
+	Height           int               `json:"height"`
+	Label            string            `json:"label,omitempty"`
+	LevelLargeText   Level             `json:"levelLargeText"`
+	LevelNonText     Level             `json:"levelNonText"`
+	LevelSmallText   Level             `json:"levelSmallText"`
+	Pass             bool              `json:"pass"`
+	RequiresFix      bool              `json:"requiresFix"`
+	Target           Target            `json:"target,omitempty"`
+	Width            int               `json:"width"`
+	X                int               `json:"x"`
+	Y                int               `json:"y"`
+}
+
+type RequirementResult struct {
+	Background       string  `json:"background"`
+	ContrastRatio    float64 `json:"contrastRatio"`
+	ContrastRatioRaw float64 `json:"contrastRatioRaw"`
+	Hex              string  `json:"hex"`
+	Level            Level   `json:"level"`
+	Name             string  `json:"name"`
+	Pass             bool    `json:"pass"`
+	Required         Level   `json:"required"`
+	Step             int     `json:"step"`
+	Target           Target  `json:"target"`
+	Theme            string  `json:"theme"`
+}
+
+type ResultPage struct {
+	Limit         int              `json:"limit"`
+	Order         string           `json:"order"`
+	Page          int              `json:"page"`
+	Pages         int              `json:"pages"`
+	Results       []ContrastResult `json:"results"`
+	Sort          string           `json:"sort"`
+	SRGBThreshold float64          `json:"srgbThreshold"`
+	Total         int              `json:"total"`
+	Totals        LevelCounts      `json:"totals"`
+	Warnings      []PaletteWarning `json:"warnings"`
+}
+
+type StepRequirement struct {
+	Background string `json:"background,omitempty"`
+	Level      Level  `json:"level"`
+	Step       int    `json:"step"`
+	Target     Target `json:"target,omitempty"`
+}
+
+type StoredPalette struct {
+	Dark      map[string]string `json:"dark"`
+	Light     map[string]string `json:"light"`
+	Name      string            `json:"name"`
+	Project   string            `json:"project"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	Usages    []UsageRule       `json:"usages,omitempty"`
+	Version   int               `json:"version"`
+}
+
+type Suggestion struct {
+	ContrastRatio float64 `json:"contrastRatio"`
+	Hex           string  `json:"hex"`
+	Level         Level   `json:"level"`
+	Role          string  `json:"role"`
+}
+
+type SurfaceReport struct {
+	Category       Category        `json:"category"`
+	ForegroundHex  string          `json:"foregroundHex"`
+	ForegroundName string          `json:"foregroundName"`
+	Limiting       string          `json:"limiting"`
+	MaxRatio       float64         `json:"maxRatio"`
+	MaxRatioRaw    float64         `json:"maxRatioRaw"`
+	MinRatio       float64         `json:"minRatio"`
+	MinRatioRaw    float64         `json:"minRatioRaw"`
+	Surfaces       []SurfaceResult `json:"surfaces"`
+}
+
+type SurfaceResult struct {
+	BackgroundHex    string            `json:"backgroundHex"`
+	BackgroundName   string            `json:"backgroundName"`
+	BackgroundTheme  string            `json:"backgroundTheme"`
+	Category         Category          `json:"category"`
+	ContrastRatio    float64           `json:"contrastRatio"`
+	ContrastRatioRaw float64           `json:"contrastRatioRaw"`
+	Criteria         []CriterionResult `json:"criteria"`
+	ForegroundHex    string            `json:"foregroundHex"`
+	ForegroundName   string            `json:"foregroundName"`
+	ForegroundTheme  string            `json:"foregroundTheme"`
+	LevelLargeText   Level             `json:"levelLargeText"`
+	LevelNonText     Level             `json:"levelNonText"`
+	LevelSmallText   Level             `json:"levelSmallText"`
+	Limiting         bool              `json:"limiting"`
+	RequiresFix      bool              `json:"requiresFix"`
+}
+
+type Target string
+
+const (
+	TargetSmallText Target = "smallText"
+	TargetLargeText Target = "largeText"
+	TargetNonText   Target = "nonText"
+)
+
+type ThemeSpec struct {
+	Background   string            `json:"background"`
+	Requirements []StepRequirement `json:"requirements,omitempty"`
+	Theme        string            `json:"theme"`
+}
+
+type ThresholdCheck struct {
+	Pass   bool    `json:"pass"`
+	Ratio  float64 `json:"ratio"`
+	Target Target  `json:"target"`
+}
+
+type UsageReport struct {
+	Algorithm string           `json:"algorithm"`
+	Usages    []UsageResult    `json:"usages"`
+	Warnings  []PaletteWarning `json:"warnings"`
+}
+
+type UsageResult struct {
+	Algorithm      string  `json:"algorithm"`
+	BackgroundHex  string  `json:"backgroundHex"`
+	BackgroundName string  `json:"backgroundName"`
+	FontSizePx     float64 `json:"fontSizePx,omitempty"`
+	FontWeight     int     `json:"fontWeight,omitempty"`
+	ForegroundHex  string  `json:"foregroundHex"`
+	ForegroundName string  `json:"foregroundName"`
+	Level          Level   `json:"level,omitempty"`
+	Name           string  `json:"name"`
+	Pass           bool    `json:"pass"`
+	Required       float64 `json:"required"`
+	Score          float64 `json:"score"`
+	ScoreRaw       float64 `json:"scoreRaw"`
+	Target         Target  `json:"target"`
+}
+
+type UsageRule struct {
+	Background string `json:"background"`
+	FontSize   string `json:"fontSize,omitempty"`
+	FontWeight int    `json:"fontWeight,omitempty"`
+	Foreground string `json:"foreground"`
+	Name       string `json:"name"`
+	NonText    bool   `json:"nonText,omitempty"`
+}
+
+type Webhook struct {
+	CreatedAt       time.Time `json:"createdAt"`
+	Disabled        bool      `json:"disabled,omitempty"`
+	ID              int64     `json:"id"`
+	Palettes        []string  `json:"palettes"`
+	Project         string    `json:"project"`
+	RegressionsOnly bool      `json:"regressionsOnly,omitempty"`
+	Secret          string    `json:"secret,omitempty"`
+	URL             string    `json:"url"`
+}
+
+type WebhookDelivery struct {
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	Error       string          `json:"error,omitempty"`
+	Event       string          `json:"event"`
+	ID          int64           `json:"id"`
+	NextAttempt time.Time       `json:"nextAttempt,omitempty"`
+	Palette     string          `json:"palette"`
+	Payload     json.RawMessage `json:"payload"`
+	State       string          `json:"state"`
+	Status      int             `json:"status"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Version     int             `json:"version"`
+	Webhook     int64           `json:"webhook"`
+}
+
+type WebhookSettings struct {
+	Disabled        bool     `json:"disabled"`
+	Palettes        []string `json:"palettes"`
+	RegressionsOnly bool     `json:"regressionsOnly"`
+	Secret          string   `json:"secret"`
+	URL             string   `json:"url"`
+}
+
+// AnalyzeImage: Estimate the text contrast of a screenshot or mockup.
+//
+// POST /api/images needs the viewer role when the server requires sign-in.
+func (c *Client) AnalyzeImage(ctx context.Context, form ImageUpload) (*ImageAnalysis, error) {
+	req := request{method: "POST", path: "/api/images", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	req.body = pr
+	req.contentType = mw.FormDataContentType()
+	go func() {
+		pw.CloseWithError(func() error {
+			if form.Image != nil {
+				w, err := mw.CreateFormFile("image", "image")
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(w, form.Image); err != nil {
+					return err
+				}
+			}
+			if form.Regions != nil {
+				data, err := json.Marshal(form.Regions)
+				if err != nil {
+					return err
+				}
+				if err := mw.WriteField("regions", string(data)); err != nil {
+					return err
+				}
+			}
+			if form.Tile != 0 {
+				if err := mw.WriteField("tile", strconv.Itoa(form.Tile)); err != nil {
+					return err
+				}
+			}
+			return mw.Close()
+		}())
+	}()
+	var out ImageAnalysis
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckIconParams are the optional parameters of CheckIcon.
+type CheckIconParams struct {
+	// Dark palette name or #rrggbb to check against, every dark color unless given.
+	Bg []string
+	// Light palette name or #rrggbb that currentColor stands for.
+	CurrentColor string
+	// Stored palette to use instead of the project's.
+	Palette string
+	// Version of the palette to use instead of the current one.
+	Version int
+}
+
+// CheckIcon: Check the colors of an SVG icon against backgrounds.
+//
+// POST /api/icons needs the viewer role when the server requires sign-in.
+func (c *Client) CheckIcon(ctx context.Context, body io.Reader, params *CheckIconParams) (*IconReport, error) {
+	req := request{method: "POST", path: "/api/icons", query: url.Values{}, header: http.Header{}, contentType: "image/svg+xml", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		for _, v := range params.Bg {
+			req.query.Add("bg", v)
+		}
+		if params.CurrentColor != "" {
+			req.query.Set("currentColor", params.CurrentColor)
+		}
+		if params.Palette != "" {
+			req.query.Set("palette", params.Palette)
+		}
+		if params.Version != 0 {
+			req.query.Set("version", strconv.Itoa(params.Version))
+		}
+	}
+	req.body = body
+	var out IconReport
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckPairParams are the optional parameters of CheckPair.
+type CheckPairParams struct {
+	// Any opaque CSS color.
+	Foreground string
+	// Any opaque CSS color.
+	Background string
+}
+
+// CheckPair: Check one pair of colors as the color picker does.
+//
+// Adds APCA, color vision deficiency previews, and the nearest colors that reach the levels the pair misses.
+//
+// GET /api/pair needs the viewer role when the server requires sign-in.
+func (c *Client) CheckPair(ctx context.Context, params *CheckPairParams) (*PairReport, error) {
+	req := request{method: "GET", path: "/api/pair", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.Foreground != "" {
+			req.query.Set("foreground", params.Foreground)
+		}
+		if params.Background != "" {
+			req.query.Set("background", params.Background)
+		}
+	}
+	var out PairReport
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckProjectParams are the optional parameters of CheckProject.
+type CheckProjectParams struct {
+	// Algorithm that judges usage rules, the project's or wcag unless given.
+	Algorithm string
+	// Stored palette to use instead of the project's.
+	Palette string
+	// Version of the palette to use instead of the current one.
+	Version int
+}
+
+// CheckProject: Hold a palette to the project's thresholds.
+//
+// Answers 200 when the check passes and 422 when it fails.
+//
+// GET /api/check needs the viewer role when the server requires sign-in.
+func (c *Client) CheckProject(ctx context.Context, params *CheckProjectParams) (*ProjectCheck, error) {
+	req := request{method: "GET", path: "/api/check", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200, 422}}
+	if params != nil {
+		if params.Algorithm != "" {
+			req.query.Set("algorithm", params.Algorithm)
+		}
+		if params.Palette != "" {
+			req.query.Set("palette", params.Palette)
+		}
+		if params.Version != 0 {
+			req.query.Set("version", strconv.Itoa(params.Version))
+		}
+	}
+	var out ProjectCheck
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckSurfacesParams are the optional parameters of CheckSurfaces.
+type CheckSurfacesParams struct {
+	// Light palette name or #rrggbb.
+	Foreground string
+	// Dark palette name or #rrggbb to check against, every dark color unless given with group.
+	Bg []string
+	// Dark palette names to check against, with * and ? wildcards.
+	Group string
+	// Stored palette to use instead of the project's.
+	Palette string
+	// Version of the palette to use instead of the current one.
+	Version int
+}
+
+// CheckSurfaces: Check one foreground on several backgrounds.
+//
+// Reports the lowest and highest ratio and the limiting surface, the one with the lowest ratio.
+//
+// GET /api/surfaces needs the viewer role when the server requires sign-in.
+func (c *Client) CheckSurfaces(ctx context.Context, params *CheckSurfacesParams) (*SurfaceReport, error) {
+	req := request{method: "GET", path: "/api/surfaces", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.Foreground != "" {
+			req.query.Set("foreground", params.Foreground)
+		}
+		for _, v := range params.Bg {
+			req.query.Add("bg", v)
+		}
+		if params.Group != "" {
+			req.query.Set("group", params.Group)
+		}
+		if params.Palette != "" {
+			req.query.Set("palette", params.Palette)
+		}
+		if params.Version != 0 {
+			req.query.Set("version", strconv.Itoa(params.Version))
+		}
+	}
+	var out SurfaceReport
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClearThemeParams are the optional parameters of ClearTheme.
+type ClearThemeParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// ClearTheme: Remove every color of one theme.
+//
+// DELETE /api/palettes/{palette}/themes/{theme} needs the editor role when the server requires sign-in.
+func (c *Client) ClearTheme(ctx context.Context, palette string, theme string, params *ClearThemeParams) error {
+	req := request{method: "DELETE", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{204}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	return c.do(ctx, req, nil)
+}
+
+// CreateProject: Create a project.
+//
+// POST /api/projects needs the admin role when the server requires sign-in.
+func (c *Client) CreateProject(ctx context.Context, body Project) (*Project, error) {
+	req := request{method: "POST", path: "/api/projects", query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: true, ok: []int{201}}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out Project
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateWebhook: Add a webhook, answering with its secret.
+//
+// POST /api/webhooks needs the admin role when the server requires sign-in.
+func (c *Client) CreateWebhook(ctx context.Context, body WebhookSettings) (*Webhook, error) {
+	req := request{method: "POST", path: "/api/webhooks", query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{201}}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out Webhook
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteColorParams are the optional parameters of DeleteColor.
+type DeleteColorParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// DeleteColor: Remove one color.
+//
+// DELETE /api/palettes/{palette}/themes/{theme}/colors/{color} needs the editor role when the server requires sign-in.
+func (c *Client) DeleteColor(ctx context.Context, palette string, theme string, color string, params *DeleteColorParams) error {
+	req := request{method: "DELETE", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme) + "/colors/" + url.PathEscape(color), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{204}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	return c.do(ctx, req, nil)
+}
+
+// DeletePaletteParams are the optional parameters of DeletePalette.
+type DeletePaletteParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// DeletePalette: Delete a palette.
+//
+// DELETE /api/palettes/{palette} needs the editor role when the server requires sign-in.
+func (c *Client) DeletePalette(ctx context.Context, palette string, params *DeletePaletteParams) error {
+	req := request{method: "DELETE", path: "/api/palettes/" + url.PathEscape(palette), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{204}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteProject: Delete a project with its palettes and history.
+//
+// DELETE /api/projects/{project} needs the admin role when the server requires sign-in.
+func (c *Client) DeleteProject(ctx context.Context, project string) error {
+	req := request{method: "DELETE", path: "/api/projects/" + url.PathEscape(project), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: true, ok: []int{204}}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteWebhook: Remove a webhook.
+//
+// DELETE /api/webhooks/{webhook} needs the admin role when the server requires sign-in.
+func (c *Client) DeleteWebhook(ctx context.Context, webhook int64) error {
+	req := request{method: "DELETE", path: "/api/webhooks/" + url.PathEscape(strconv.FormatInt(webhook, 10)), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{204}}
+	return c.do(ctx, req, nil)
+}
+
+// ExportProject: The project with all of its palettes.
+//
+// GET /api/export needs the viewer role when the server requires sign-in.
+func (c *Client) ExportProject(ctx context.Context) (*ProjectExport, error) {
+	req := request{method: "GET", path: "/api/export", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out ProjectExport
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GeneratePalette: Generate a palette from a seed color.
+//
+// POST /api/palettes needs the viewer role when the server requires sign-in.
+func (c *Client) GeneratePalette(ctx context.Context, body PaletteSpec) (*GeneratedPalette, error) {
+	req := request{method: "POST", path: "/api/palettes", query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{200}}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out GeneratedPalette
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetColor: One color.
+//
+// GET /api/palettes/{palette}/themes/{theme}/colors/{color} needs the viewer role when the server requires sign-in.
+func (c *Client) GetColor(ctx context.Context, palette string, theme string, color string) (*PaletteColor, error) {
+	req := request{method: "GET", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme) + "/colors/" + url.PathEscape(color), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out PaletteColor
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOpenAPI: This document.
+//
+// GET /api/openapi.json needs the viewer role when the server requires sign-in.
+func (c *Client) GetOpenAPI(ctx context.Context) (json.RawMessage, error) {
+	req := request{method: "GET", path: "/api/openapi.json", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: true, ok: []int{200}}
+	var out json.RawMessage
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPalette: A stored palette.
+//
+// GET /api/palettes/{palette} needs the viewer role when the server requires sign-in.
+func (c *Client) GetPalette(ctx context.Context, palette string) (*StoredPalette, error) {
+	req := request{method: "GET", path: "/api/palettes/" + url.PathEscape(palette), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out StoredPalette
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProject: A project.
+//
+// GET /api/projects/{project} needs the viewer role when the server requires sign-in.
+func (c *Client) GetProject(ctx context.Context, project string) (*Project, error) {
+	req := request{method: "GET", path: "/api/projects/" + url.PathEscape(project), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: true, ok: []int{200}}
+	var out Project
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTheme: The colors of one theme.
+//
+// GET /api/palettes/{palette}/themes/{theme} needs the viewer role when the server requires sign-in.
+func (c *Client) GetTheme(ctx context.Context, palette string, theme string) (map[string]string, error) {
+	req := request{method: "GET", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out map[string]string
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetVersion: One version of a palette with its colors.
+//
+// GET /api/palettes/{palette}/versions/{version} needs the viewer role when the server requires sign-in.
+func (c *Client) GetVersion(ctx context.Context, palette string, version int) (*PaletteVersion, error) {
+	req := request{method: "GET", path: "/api/palettes/" + url.PathEscape(palette) + "/versions/" + url.PathEscape(strconv.Itoa(version)), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out PaletteVersion
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWebhook: A webhook.
+//
+// GET /api/webhooks/{webhook} needs the admin role when the server requires sign-in.
+func (c *Client) GetWebhook(ctx context.Context, webhook int64) (*Webhook, error) {
+	req := request{method: "GET", path: "/api/webhooks/" + url.PathEscape(strconv.FormatInt(webhook, 10)), query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out Webhook
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListContrastsParams are the optional parameters of ListContrasts.
+type ListContrastsParams struct {
+	// Search expression, as described in the README.
+	Q string
+	// Only results in this category.
+	Filter string
+	// Field to sort by, foreground unless given.
+	Sort string
+	// Sort order, asc unless given.
+	Order string
+	// Page number, from 1.
+	Page int
+	// Results per page, at most 1000.
+	Limit int
+	// Algorithm that judges usage rules, the project's or wcag unless given.
+	Algorithm string
+	// Stored palette to use instead of the project's.
+	Palette string
+	// Version of the palette to use instead of the current one.
+	Version int
+}
+
+// ListContrasts: One page of contrast results.
+//
+// GET /api/contrasts needs the viewer role when the server requires sign-in.
+func (c *Client) ListContrasts(ctx context.Context, params *ListContrastsParams) (*ResultPage, error) {
+	req := request{method: "GET", path: "/api/contrasts", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.Q != "" {
+			req.query.Set("q", params.Q)
+		}
+		if params.Filter != "" {
+			req.query.Set("filter", params.Filter)
+		}
+		if params.Sort != "" {
+			req.query.Set("sort", params.Sort)
+		}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+		if params.Page != 0 {
+			req.query.Set("page", strconv.Itoa(params.Page))
+		}
+		if params.Limit != 0 {
+			req.query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Algorithm != "" {
+			req.query.Set("algorithm", params.Algorithm)
+		}
+		if params.Palette != "" {
+			req.query.Set("palette", params.Palette)
+		}
+		if params.Version != 0 {
+			req.query.Set("version", strconv.Itoa(params.Version))
+		}
+	}
+	var out ResultPage
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDeliveriesParams are the optional parameters of ListDeliveries.
+type ListDeliveriesParams struct {
+	// Deliveries to return, 50 unless given and at most 500.
+	Limit int
+}
+
+// ListDeliveries: The newest deliveries of a webhook.
+//
+// GET /api/webhooks/{webhook}/deliveries needs the admin role when the server requires sign-in.
+func (c *Client) ListDeliveries(ctx context.Context, webhook int64, params *ListDeliveriesParams) ([]WebhookDelivery, error) {
+	req := request{method: "GET", path: "/api/webhooks/" + url.PathEscape(strconv.FormatInt(webhook, 10)) + "/deliveries", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.Limit != 0 {
+			req.query.Set("limit", strconv.Itoa(params.Limit))
+		}
+	}
+	var out []WebhookDelivery
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListPalettes: The project's stored palettes.
+//
+// GET /api/palettes needs the viewer role when the server requires sign-in.
+func (c *Client) ListPalettes(ctx context.Context) ([]StoredPalette, error) {
+	req := request{method: "GET", path: "/api/palettes", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out []StoredPalette
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProjects: Every project.
+//
+// GET /api/projects needs the viewer role when the server requires sign-in.
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	req := request{method: "GET", path: "/api/projects", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: true, ok: []int{200}}
+	var out []Project
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListUsagesParams are the optional parameters of ListUsages.
+type ListUsagesParams struct {
+	// Algorithm that judges usage rules, the project's or wcag unless given.
+	Algorithm string
+	// Stored palette to use instead of the project's.
+	Palette string
+	// Version of the palette to use instead of the current one.
+	Version int
+}
+
+// ListUsages: Verdicts on the palette's usage rules.
+//
+// GET /api/usages needs the viewer role when the server requires sign-in.
+func (c *Client) ListUsages(ctx context.Context, params *ListUsagesParams) (*UsageReport, error) {
+	req := request{method: "GET", path: "/api/usages", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.Algorithm != "" {
+			req.query.Set("algorithm", params.Algorithm)
+		}
+		if params.Palette != "" {
+			req.query.Set("palette", params.Palette)
+		}
+		if params.Version != 0 {
+			req.query.Set("version", strconv.Itoa(params.Version))
+		}
+	}
+	var out UsageReport
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListVersions: The history of a palette, newest first.
+//
+// GET /api/palettes/{palette}/versions needs the viewer role when the server requires sign-in.
+func (c *Client) ListVersions(ctx context.Context, palette string) ([]PaletteVersion, error) {
+	req := request{method: "GET", path: "/api/palettes/" + url.PathEscape(palette) + "/versions", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out []PaletteVersion
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWebhooks: The project's webhooks.
+//
+// GET /api/webhooks needs the admin role when the server requires sign-in.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	req := request{method: "GET", path: "/api/webhooks", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	var out []Webhook
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutColorParams are the optional parameters of PutColor.
+type PutColorParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// PutColor: Add or change one color.
+//
+// The value may be any opaque CSS color and is stored as #rrggbb.
+//
+// PUT /api/palettes/{palette}/themes/{theme}/colors/{color} needs the editor role when the server requires sign-in.
+func (c *Client) PutColor(ctx context.Context, palette string, theme string, color string, body ColorValue, params *PutColorParams) (*PaletteColor, error) {
+	req := request{method: "PUT", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme) + "/colors/" + url.PathEscape(color), query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{200, 201}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out PaletteColor
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PutPaletteParams are the optional parameters of PutPalette.
+type PutPaletteParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// PutPalette: Create a palette, or replace it with If-Match.
+//
+// PUT /api/palettes/{palette} needs the editor role when the server requires sign-in.
+func (c *Client) PutPalette(ctx context.Context, palette string, body ColorSets, params *PutPaletteParams) (*StoredPalette, error) {
+	req := request{method: "PUT", path: "/api/palettes/" + url.PathEscape(palette), query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{200, 201}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out StoredPalette
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PutThemeParams are the optional parameters of PutTheme.
+type PutThemeParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// PutTheme: Replace the colors of one theme.
+//
+// PUT /api/palettes/{palette}/themes/{theme} needs the editor role when the server requires sign-in.
+func (c *Client) PutTheme(ctx context.Context, palette string, theme string, body map[string]string, params *PutThemeParams) (map[string]string, error) {
+	req := request{method: "PUT", path: "/api/palettes/" + url.PathEscape(palette) + "/themes/" + url.PathEscape(theme), query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out map[string]string
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RenderHeatmap: The contrast heatmap of a screenshot or mockup.
+//
+// POST /api/images/heatmap.png needs the viewer role when the server requires sign-in.
+func (c *Client) RenderHeatmap(ctx context.Context, form ImageUpload) ([]byte, error) {
+	req := request{method: "POST", path: "/api/images/heatmap.png", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	req.body = pr
+	req.contentType = mw.FormDataContentType()
+	go func() {
+		pw.CloseWithError(func() error {
+			if form.Image != nil {
+				w, err := mw.CreateFormFile("image", "image")
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(w, form.Image); err != nil {
+					return err
+				}
+			}
+			if form.Regions != nil {
+				data, err := json.Marshal(form.Regions)
+				if err != nil {
+					return err
+				}
+				if err := mw.WriteField("regions", string(data)); err != nil {
+					return err
+				}
+			}
+			if form.Tile != 0 {
+				if err := mw.WriteField("tile", strconv.Itoa(form.Tile)); err != nil {
+					return err
+				}
+			}
+			return mw.Close()
+		}())
+	}()
+	var out []byte
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RollbackPaletteParams are the optional parameters of RollbackPalette.
+type RollbackPaletteParams struct {
+	// ETag of the version the change is based on, or "*" for any version.
+	IfMatch string
+	// Author recorded in the history when the request is not signed in.
+	Author string
+	// Change note recorded in the history.
+	Message string
+}
+
+// RollbackPalette: Save the colors of an earlier version as the next version.
+//
+// Like a PUT of the whole palette, it needs If-Match unless the palette has been deleted.
+//
+// POST /api/palettes/{palette}/versions/{version}/rollback needs the editor role when the server requires sign-in.
+func (c *Client) RollbackPalette(ctx context.Context, palette string, version int, params *RollbackPaletteParams) (*StoredPalette, error) {
+	req := request{method: "POST", path: "/api/palettes/" + url.PathEscape(palette) + "/versions/" + url.PathEscape(strconv.Itoa(version)) + "/rollback", query: url.Values{}, header: http.Header{}, contentType: "", rootOnly: false, ok: []int{200}}
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+		if params.Author != "" {
+			req.query.Set("author", params.Author)
+		}
+		if params.Message != "" {
+			req.query.Set("message", params.Message)
+		}
+	}
+	var out StoredPalette
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveProject: Change a project, creating it if needed.
+//
+// PUT /api/projects/{project} needs the admin role when the server requires sign-in.
+func (c *Client) SaveProject(ctx context.Context, project string, body ProjectUpdate) (*Project, error) {
+	req := request{method: "PUT", path: "/api/projects/" + url.PathEscape(project), query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: true, ok: []int{200, 201}}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out Project
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateWebhook: Replace the settings of a webhook.
+//
+// The secret is kept unless a new one is given.
+//
+// PUT /api/webhooks/{webhook} needs the admin role when the server requires sign-in.
+func (c *Client) UpdateWebhook(ctx context.Context, webhook int64, body WebhookSettings) (*Webhook, error) {
+	req := request{method: "PUT", path: "/api/webhooks/" + url.PathEscape(strconv.FormatInt(webhook, 10)), query: url.Values{}, header: http.Header{}, contentType: "application/json", rootOnly: false, ok: []int{200}}
+	reader, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = reader
+	var out Webhook
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
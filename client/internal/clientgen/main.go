@@ -0,0 +1,447 @@
+// Command clientgen writes the types and methods of package client from the
+// server's OpenAPI document. It supports the parts of OpenAPI the server
+// uses, and fails on anything else so the client cannot silently fall
+// behind.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []string           `json:"enum"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AllOf                []*schema          `json:"allOf"`
+	Nullable             bool               `json:"nullable"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema   *schema `json:"schema"`
+	Encoding map[string]struct {
+		ContentType string `json:"contentType"`
+	} `json:"encoding"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Description string      `json:"description"`
+	Role        string      `json:"x-role"`
+	RootOnly    bool        `json:"x-root-only"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+
+	method, path string
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+func main() {
+	out := flag.String("o", "client_gen.go", "file to write")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: clientgen [-o file] openapi.json")
+		os.Exit(2)
+	}
+	data, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		fail(err)
+	}
+
+	g := &generator{schemas: doc.Components.Schemas}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.typeDecl(name, doc.Components.Schemas[name])
+	}
+
+	var ops []*operation
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if method == "servers" {
+				continue
+			}
+			op := &operation{method: strings.ToUpper(method), path: path}
+			if err := json.Unmarshal(raw, op); err != nil {
+				fail(fmt.Errorf("%s %s: %w", method, path, err))
+			}
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	for _, op := range ops {
+		g.method(op)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by clientgen from openapi.json. DO NOT EDIT.\n\npackage client\n\nimport (\n")
+	for _, pkg := range []string{"context", "encoding/json", "io", "mime/multipart", "net/http", "net/url", "strconv", "time"} {
+		name := pkg[strings.LastIndex(pkg, "/")+1:]
+		if regexp.MustCompile(`\b` + name + `\.[A-Z]`).Match(g.buf.Bytes()) {
+			fmt.Fprintf(&src, "%q\n", pkg)
+		}
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.buf.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		os.Stdout.Write(src.Bytes())
+		fail(err)
+	}
+	if err := os.WriteFile(*out, formatted, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "clientgen:", err)
+	os.Exit(1)
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	schemas map[string]*schema
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// initialisms are written in capitals in Go names.
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true, "sha256": true, "srgb": true, "api": true, "json": true}
+
+// goName turns a JSON or parameter name into an exported Go name.
+func goName(name string) string {
+	var words []string
+	start := 0
+	runes := []rune(name)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '-' || runes[i] == '_' || unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			words = append(words, strings.Trim(string(runes[start:i]), "-_"))
+			start = i
+		}
+	}
+	var b strings.Builder
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		if len(r) > 0 {
+			b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+		}
+	}
+	return b.String()
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// goType returns the Go type of a schema.
+func (g *generator) goType(s *schema) string {
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case len(s.AllOf) == 1:
+		return g.goType(s.AllOf[0])
+	}
+	switch s.Type {
+	case "":
+		return "json.RawMessage"
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "time.Time"
+		case "binary":
+			return "io.Reader"
+		}
+		return "string"
+	case "boolean":
+		return "bool"
+	case "integer":
+		if s.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil && s.Properties == nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+	}
+	fail(fmt.Errorf("unsupported schema %+v", *s))
+	return ""
+}
+
+// isStruct reports whether a schema is generated as a struct.
+func (g *generator) isStruct(s *schema) bool {
+	if s.Ref == "" {
+		return false
+	}
+	target := g.schemas[refName(s.Ref)]
+	return target.Type == "object" && target.Properties != nil
+}
+
+func (g *generator) typeDecl(name string, s *schema) {
+	if s.Enum != nil {
+		g.printf("type %s string\n\nconst (\n", name)
+		for _, v := range s.Enum {
+			g.printf("%s%s %s = %q\n", name, goName(v), name, v)
+		}
+		g.printf(")\n\n")
+		return
+	}
+	if s.Type != "object" || s.Properties == nil {
+		fail(fmt.Errorf("schema %s: only objects and string enums are supported", name))
+	}
+	props := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	g.printf("type %s struct {\n", name)
+	for _, prop := range props {
+		p := s.Properties[prop]
+		typ := g.goType(p)
+		tag := prop
+		switch {
+		case typ == "io.Reader":
+			tag = "-"
+		case len(p.AllOf) == 1 && p.Nullable:
+			typ = "*" + typ
+		case !slices.Contains(s.Required, prop):
+			if g.isStruct(p) {
+				typ = "*" + typ
+			}
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `json:%q`\n", goName(prop), typ, tag)
+	}
+	g.printf("}\n\n")
+}
+
+// method writes the client method of an operation, with its parameter
+// struct when it has query or header parameters.
+func (g *generator) method(op *operation) {
+	var pathParams, otherParams []parameter
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			pathParams = append(pathParams, p)
+		} else {
+			otherParams = append(otherParams, p)
+		}
+	}
+
+	paramsType := op.OperationID + "Params"
+	if len(otherParams) > 0 {
+		g.printf("// %s are the optional parameters of %s.\ntype %s struct {\n", paramsType, op.OperationID, paramsType)
+		for _, p := range otherParams {
+			g.printf("// %s\n%s %s\n", strings.ToUpper(p.Description[:1])+p.Description[1:]+".", goName(p.Name), g.goType(p.Schema))
+		}
+		g.printf("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathParams {
+		args = append(args, fmt.Sprintf("%s %s", lowerName(p.Name), g.goType(p.Schema)))
+	}
+
+	// The request body.
+	var bodyCode, contentType string
+	if op.RequestBody != nil {
+		for ct, media := range op.RequestBody.Content {
+			contentType = ct
+			switch {
+			case ct == "application/json":
+				args = append(args, "body "+g.goType(media.Schema))
+				bodyCode = "reader, err := jsonBody(body)\nif err != nil {\nreturn nil, err\n}\nreq.body = reader\n"
+			case ct == "multipart/form-data":
+				args = append(args, "form "+g.goType(media.Schema))
+				bodyCode = g.multipart(media)
+				contentType = ""
+			default:
+				args = append(args, "body io.Reader")
+				bodyCode = "req.body = body\n"
+			}
+		}
+	}
+	if len(otherParams) > 0 {
+		args = append(args, "params *"+paramsType)
+	}
+
+	// The result, from the success responses.
+	var ok []string
+	var result string
+	var codes []string
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if code == "default" {
+			continue
+		}
+		ok = append(ok, code)
+		for ct, media := range op.Responses[code].Content {
+			switch ct {
+			case "application/json":
+				result = g.goType(media.Schema)
+				if g.isStruct(media.Schema) {
+					result = "*" + result
+				}
+			default:
+				result = "[]byte"
+			}
+		}
+	}
+
+	doc := op.Summary + "."
+	if op.Description != "" {
+		doc += "\n//\n// " + op.Description
+	}
+	g.printf("// %s: %s\n//\n// %s %s needs the %s role when the server requires sign-in.\n", op.OperationID, doc, op.method, op.path, op.Role)
+	if result == "" {
+		g.printf("func (c *Client) %s(%s) error {\n", op.OperationID, strings.Join(args, ", "))
+	} else {
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", op.OperationID, strings.Join(args, ", "), result)
+	}
+
+	path := fmt.Sprintf("%q", op.path)
+	for _, p := range pathParams {
+		value := lowerName(p.Name)
+		switch g.goType(p.Schema) {
+		case "int":
+			value = "strconv.Itoa(" + value + ")"
+		case "int64":
+			value = "strconv.FormatInt(" + value + ", 10)"
+		}
+		path = strings.Replace(path, "{"+p.Name+"}", `" + url.PathEscape(`+value+`) + "`, 1)
+	}
+	path = strings.TrimSuffix(strings.TrimPrefix(path, `"" + `), ` + ""`)
+	g.printf("req := request{method: %q, path: %s, query: url.Values{}, header: http.Header{}, contentType: %q, rootOnly: %v, ok: []int{%s}}\n",
+		op.method, path, contentType, op.RootOnly, strings.Join(ok, ", "))
+
+	if len(otherParams) > 0 {
+		g.printf("if params != nil {\n")
+		for _, p := range otherParams {
+			field := "params." + goName(p.Name)
+			set := fmt.Sprintf("req.query.Set(%q, %%s)", p.Name)
+			if p.In == "header" {
+				set = fmt.Sprintf("req.header.Set(%q, %%s)", p.Name)
+			}
+			switch g.goType(p.Schema) {
+			case "string":
+				g.printf("if %s != \"\" {\n%s\n}\n", field, fmt.Sprintf(set, field))
+			case "int":
+				g.printf("if %s != 0 {\n%s\n}\n", field, fmt.Sprintf(set, "strconv.Itoa("+field+")"))
+			case "[]string":
+				g.printf("for _, v := range %s {\nreq.query.Add(%q, v)\n}\n", field, p.Name)
+			default:
+				fail(fmt.Errorf("%s: unsupported parameter %s", op.OperationID, p.Name))
+			}
+		}
+		g.printf("}\n")
+	}
+	if result == "" {
+		bodyCode = strings.ReplaceAll(bodyCode, "return nil, err", "return err")
+	}
+	g.printf("%s", bodyCode)
+
+	switch {
+	case result == "":
+		g.printf("return c.do(ctx, req, nil)\n}\n\n")
+	case strings.HasPrefix(result, "*"):
+		g.printf("var out %s\nif err := c.do(ctx, req, &out); err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n\n", result[1:])
+	default:
+		g.printf("var out %s\nif err := c.do(ctx, req, &out); err != nil {\nreturn nil, err\n}\nreturn out, nil\n}\n\n", result)
+	}
+}
+
+// multipart returns the code that encodes a form as the request body.
+func (g *generator) multipart(media mediaType) string {
+	s := g.schemas[refName(media.Schema.Ref)]
+	props := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	var b strings.Builder
+	b.WriteString("pr, pw := io.Pipe()\nmw := multipart.NewWriter(pw)\nreq.body = pr\nreq.contentType = mw.FormDataContentType()\n")
+	b.WriteString("go func() {\npw.CloseWithError(func() error {\n")
+	for _, prop := range props {
+		p := s.Properties[prop]
+		field := "form." + goName(prop)
+		switch typ := g.goType(p); {
+		case typ == "io.Reader":
+			fmt.Fprintf(&b, "if %s != nil {\nw, err := mw.CreateFormFile(%q, %q)\nif err != nil {\nreturn err\n}\nif _, err := io.Copy(w, %s); err != nil {\nreturn err\n}\n}\n", field, prop, prop, field)
+		case media.Encoding[prop].ContentType == "application/json":
+			fmt.Fprintf(&b, "if %s != nil {\ndata, err := json.Marshal(%s)\nif err != nil {\nreturn err\n}\nif err := mw.WriteField(%q, string(data)); err != nil {\nreturn err\n}\n}\n", field, field, prop)
+		case typ == "int":
+			fmt.Fprintf(&b, "if %s != 0 {\nif err := mw.WriteField(%q, strconv.Itoa(%s)); err != nil {\nreturn err\n}\n}\n", field, prop, field)
+		case typ == "string":
+			fmt.Fprintf(&b, "if %s != \"\" {\nif err := mw.WriteField(%q, %s); err != nil {\nreturn err\n}\n}\n", field, prop, field)
+		default:
+			fail(fmt.Errorf("unsupported form field %s", prop))
+		}
+	}
+	b.WriteString("return mw.Close()\n}())\n}()\n")
+	return b.String()
+}
+
+func lowerName(name string) string {
+	n := goName(name)
+	switch {
+	case strings.ToUpper(n) == n:
+		return strings.ToLower(n)
+	default:
+		return strings.ToLower(n[:1]) + n[1:]
+	}
+}
@@ -0,0 +1,1363 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: contrast.proto
+
+// The gRPC interface of the contrast checker. Its messages carry the same
+// values as the JSON API: ContrastResult matches the JSON ContrastResult and
+// PaletteReport the JSON ProjectCheck.
+
+package contrastpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Level is a WCAG conformance level.
+type Level int32
+
+const (
+	Level_LEVEL_UNSPECIFIED Level = 0
+	Level_LEVEL_FAIL        Level = 1
+	Level_LEVEL_AA          Level = 2
+	Level_LEVEL_AAA         Level = 3
+)
+
+// Enum value maps for Level.
+var (
+	Level_name = map[int32]string{
+		0: "LEVEL_UNSPECIFIED",
+		1: "LEVEL_FAIL",
+		2: "LEVEL_AA",
+		3: "LEVEL_AAA",
+	}
+	Level_value = map[string]int32{
+		"LEVEL_UNSPECIFIED": 0,
+		"LEVEL_FAIL":        1,
+		"LEVEL_AA":          2,
+		"LEVEL_AAA":         3,
+	}
+)
+
+func (x Level) Enum() *Level {
+	p := new(Level)
+	*p = x
+	return p
+}
+
+func (x Level) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Level) Descriptor() protoreflect.EnumDescriptor {
+	return file_contrast_proto_enumTypes[0].Descriptor()
+}
+
+func (Level) Type() protoreflect.EnumType {
+	return &file_contrast_proto_enumTypes[0]
+}
+
+func (x Level) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Level.Descriptor instead.
+func (Level) EnumDescriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{0}
+}
+
+// Category is the single group a result is listed under.
+type Category int32
+
+const (
+	Category_CATEGORY_UNSPECIFIED Category = 0
+	Category_CATEGORY_FAIL        Category = 1
+	Category_CATEGORY_AA_LARGE    Category = 2
+	Category_CATEGORY_AA          Category = 3
+	Category_CATEGORY_AAA         Category = 4
+)
+
+// Enum value maps for Category.
+var (
+	Category_name = map[int32]string{
+		0: "CATEGORY_UNSPECIFIED",
+		1: "CATEGORY_FAIL",
+		2: "CATEGORY_AA_LARGE",
+		3: "CATEGORY_AA",
+		4: "CATEGORY_AAA",
+	}
+	Category_value = map[string]int32{
+		"CATEGORY_UNSPECIFIED": 0,
+		"CATEGORY_FAIL":        1,
+		"CATEGORY_AA_LARGE":    2,
+		"CATEGORY_AA":          3,
+		"CATEGORY_AAA":         4,
+	}
+)
+
+func (x Category) Enum() *Category {
+	p := new(Category)
+	*p = x
+	return p
+}
+
+func (x Category) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Category) Descriptor() protoreflect.EnumDescriptor {
+	return file_contrast_proto_enumTypes[1].Descriptor()
+}
+
+func (Category) Type() protoreflect.EnumType {
+	return &file_contrast_proto_enumTypes[1]
+}
+
+func (x Category) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Category.Descriptor instead.
+func (Category) EnumDescriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{1}
+}
+
+// Target is the kind of content a threshold applies to.
+type Target int32
+
+const (
+	Target_TARGET_UNSPECIFIED Target = 0
+	Target_TARGET_SMALL_TEXT  Target = 1
+	Target_TARGET_LARGE_TEXT  Target = 2
+	Target_TARGET_NON_TEXT    Target = 3
+)
+
+// Enum value maps for Target.
+var (
+	Target_name = map[int32]string{
+		0: "TARGET_UNSPECIFIED",
+		1: "TARGET_SMALL_TEXT",
+		2: "TARGET_LARGE_TEXT",
+		3: "TARGET_NON_TEXT",
+	}
+	Target_value = map[string]int32{
+		"TARGET_UNSPECIFIED": 0,
+		"TARGET_SMALL_TEXT":  1,
+		"TARGET_LARGE_TEXT":  2,
+		"TARGET_NON_TEXT":    3,
+	}
+)
+
+func (x Target) Enum() *Target {
+	p := new(Target)
+	*p = x
+	return p
+}
+
+func (x Target) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Target) Descriptor() protoreflect.EnumDescriptor {
+	return file_contrast_proto_enumTypes[2].Descriptor()
+}
+
+func (Target) Type() protoreflect.EnumType {
+	return &file_contrast_proto_enumTypes[2]
+}
+
+func (x Target) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Target.Descriptor instead.
+func (Target) EnumDescriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{2}
+}
+
+type CheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Foreground and background are any opaque CSS colors.
+	Foreground string `protobuf:"bytes,1,opt,name=foreground,proto3" json:"foreground,omitempty"`
+	Background string `protobuf:"bytes,2,opt,name=background,proto3" json:"background,omitempty"`
+	// ID is copied to the response, to match answers in a batch.
+	Id            string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_contrast_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckRequest) GetForeground() string {
+	if x != nil {
+		return x.Foreground
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetBackground() string {
+	if x != nil {
+		return x.Background
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Result        *ContrastResult        `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_contrast_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckResponse) GetResult() *ContrastResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ContrastResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ForegroundHex   string                 `protobuf:"bytes,1,opt,name=foreground_hex,json=foregroundHex,proto3" json:"foreground_hex,omitempty"`
+	ForegroundName  string                 `protobuf:"bytes,2,opt,name=foreground_name,json=foregroundName,proto3" json:"foreground_name,omitempty"`
+	ForegroundTheme string                 `protobuf:"bytes,3,opt,name=foreground_theme,json=foregroundTheme,proto3" json:"foreground_theme,omitempty"`
+	BackgroundHex   string                 `protobuf:"bytes,4,opt,name=background_hex,json=backgroundHex,proto3" json:"background_hex,omitempty"`
+	BackgroundName  string                 `protobuf:"bytes,5,opt,name=background_name,json=backgroundName,proto3" json:"background_name,omitempty"`
+	BackgroundTheme string                 `protobuf:"bytes,6,opt,name=background_theme,json=backgroundTheme,proto3" json:"background_theme,omitempty"`
+	// contrast_ratio is contrast_ratio_raw truncated to two decimals. Levels
+	// always use contrast_ratio_raw.
+	ContrastRatio    float64            `protobuf:"fixed64,7,opt,name=contrast_ratio,json=contrastRatio,proto3" json:"contrast_ratio,omitempty"`
+	ContrastRatioRaw float64            `protobuf:"fixed64,8,opt,name=contrast_ratio_raw,json=contrastRatioRaw,proto3" json:"contrast_ratio_raw,omitempty"`
+	LevelSmallText   Level              `protobuf:"varint,9,opt,name=level_small_text,json=levelSmallText,proto3,enum=contrast.v1.Level" json:"level_small_text,omitempty"`
+	LevelLargeText   Level              `protobuf:"varint,10,opt,name=level_large_text,json=levelLargeText,proto3,enum=contrast.v1.Level" json:"level_large_text,omitempty"`
+	LevelNonText     Level              `protobuf:"varint,11,opt,name=level_non_text,json=levelNonText,proto3,enum=contrast.v1.Level" json:"level_non_text,omitempty"`
+	Category         Category           `protobuf:"varint,12,opt,name=category,proto3,enum=contrast.v1.Category" json:"category,omitempty"`
+	Criteria         []*CriterionResult `protobuf:"bytes,13,rep,name=criteria,proto3" json:"criteria,omitempty"`
+	RequiresFix      bool               `protobuf:"varint,14,opt,name=requires_fix,json=requiresFix,proto3" json:"requires_fix,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ContrastResult) Reset() {
+	*x = ContrastResult{}
+	mi := &file_contrast_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContrastResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContrastResult) ProtoMessage() {}
+
+func (x *ContrastResult) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContrastResult.ProtoReflect.Descriptor instead.
+func (*ContrastResult) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ContrastResult) GetForegroundHex() string {
+	if x != nil {
+		return x.ForegroundHex
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetForegroundName() string {
+	if x != nil {
+		return x.ForegroundName
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetForegroundTheme() string {
+	if x != nil {
+		return x.ForegroundTheme
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetBackgroundHex() string {
+	if x != nil {
+		return x.BackgroundHex
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetBackgroundName() string {
+	if x != nil {
+		return x.BackgroundName
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetBackgroundTheme() string {
+	if x != nil {
+		return x.BackgroundTheme
+	}
+	return ""
+}
+
+func (x *ContrastResult) GetContrastRatio() float64 {
+	if x != nil {
+		return x.ContrastRatio
+	}
+	return 0
+}
+
+func (x *ContrastResult) GetContrastRatioRaw() float64 {
+	if x != nil {
+		return x.ContrastRatioRaw
+	}
+	return 0
+}
+
+func (x *ContrastResult) GetLevelSmallText() Level {
+	if x != nil {
+		return x.LevelSmallText
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *ContrastResult) GetLevelLargeText() Level {
+	if x != nil {
+		return x.LevelLargeText
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *ContrastResult) GetLevelNonText() Level {
+	if x != nil {
+		return x.LevelNonText
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *ContrastResult) GetCategory() Category {
+	if x != nil {
+		return x.Category
+	}
+	return Category_CATEGORY_UNSPECIFIED
+}
+
+func (x *ContrastResult) GetCriteria() []*CriterionResult {
+	if x != nil {
+		return x.Criteria
+	}
+	return nil
+}
+
+func (x *ContrastResult) GetRequiresFix() bool {
+	if x != nil {
+		return x.RequiresFix
+	}
+	return false
+}
+
+type CriterionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Level         Level                  `protobuf:"varint,3,opt,name=level,proto3,enum=contrast.v1.Level" json:"level,omitempty"`
+	Checks        []*ThresholdCheck      `protobuf:"bytes,5,rep,name=checks,proto3" json:"checks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CriterionResult) Reset() {
+	*x = CriterionResult{}
+	mi := &file_contrast_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CriterionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CriterionResult) ProtoMessage() {}
+
+func (x *CriterionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CriterionResult.ProtoReflect.Descriptor instead.
+func (*CriterionResult) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CriterionResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CriterionResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CriterionResult) GetLevel() Level {
+	if x != nil {
+		return x.Level
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *CriterionResult) GetChecks() []*ThresholdCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+type ThresholdCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        Target                 `protobuf:"varint,1,opt,name=target,proto3,enum=contrast.v1.Target" json:"target,omitempty"`
+	Ratio         float64                `protobuf:"fixed64,2,opt,name=ratio,proto3" json:"ratio,omitempty"`
+	Pass          bool                   `protobuf:"varint,3,opt,name=pass,proto3" json:"pass,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThresholdCheck) Reset() {
+	*x = ThresholdCheck{}
+	mi := &file_contrast_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThresholdCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThresholdCheck) ProtoMessage() {}
+
+func (x *ThresholdCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThresholdCheck.ProtoReflect.Descriptor instead.
+func (*ThresholdCheck) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ThresholdCheck) GetTarget() Target {
+	if x != nil {
+		return x.Target
+	}
+	return Target_TARGET_UNSPECIFIED
+}
+
+func (x *ThresholdCheck) GetRatio() float64 {
+	if x != nil {
+		return x.Ratio
+	}
+	return 0
+}
+
+func (x *ThresholdCheck) GetPass() bool {
+	if x != nil {
+		return x.Pass
+	}
+	return false
+}
+
+type GetPaletteReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Project is the project ID, the default project unless given.
+	Project string `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	// Palette is the stored palette, the project's unless given.
+	Palette string `protobuf:"bytes,2,opt,name=palette,proto3" json:"palette,omitempty"`
+	// Version is the palette version, the current one unless given.
+	Version int32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Algorithm judges usage rules: wcag or apca, the project's unless given.
+	Algorithm string `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	// Query is a search expression, as in the JSON API, that selects the
+	// pairs listed in the report. It does not change the check.
+	Query         string `protobuf:"bytes,5,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaletteReportRequest) Reset() {
+	*x = GetPaletteReportRequest{}
+	mi := &file_contrast_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaletteReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaletteReportRequest) ProtoMessage() {}
+
+func (x *GetPaletteReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaletteReportRequest.ProtoReflect.Descriptor instead.
+func (*GetPaletteReportRequest) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPaletteReportRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *GetPaletteReportRequest) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *GetPaletteReportRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *GetPaletteReportRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *GetPaletteReportRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type PaletteReport struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Project string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Palette string                 `protobuf:"bytes,2,opt,name=palette,proto3" json:"palette,omitempty"`
+	Version int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Pass    bool                   `protobuf:"varint,4,opt,name=pass,proto3" json:"pass,omitempty"`
+	// Problems says why the check failed, one threshold per entry.
+	Problems      []string          `protobuf:"bytes,5,rep,name=problems,proto3" json:"problems,omitempty"`
+	Totals        *LevelCounts      `protobuf:"bytes,6,opt,name=totals,proto3" json:"totals,omitempty"`
+	FailingUsages []*UsageResult    `protobuf:"bytes,7,rep,name=failing_usages,json=failingUsages,proto3" json:"failing_usages,omitempty"`
+	Warnings      []*PaletteWarning `protobuf:"bytes,8,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	UsageWarnings []*PaletteWarning `protobuf:"bytes,9,rep,name=usage_warnings,json=usageWarnings,proto3" json:"usage_warnings,omitempty"`
+	Settings      *ProjectSettings  `protobuf:"bytes,10,opt,name=settings,proto3" json:"settings,omitempty"`
+	Algorithm     string            `protobuf:"bytes,11,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	// Results are the pairs selected by the query, sorted by foreground.
+	Results       []*ContrastResult `protobuf:"bytes,12,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaletteReport) Reset() {
+	*x = PaletteReport{}
+	mi := &file_contrast_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaletteReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaletteReport) ProtoMessage() {}
+
+func (x *PaletteReport) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaletteReport.ProtoReflect.Descriptor instead.
+func (*PaletteReport) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PaletteReport) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *PaletteReport) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *PaletteReport) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PaletteReport) GetPass() bool {
+	if x != nil {
+		return x.Pass
+	}
+	return false
+}
+
+func (x *PaletteReport) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetTotals() *LevelCounts {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetFailingUsages() []*UsageResult {
+	if x != nil {
+		return x.FailingUsages
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetWarnings() []*PaletteWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetUsageWarnings() []*PaletteWarning {
+	if x != nil {
+		return x.UsageWarnings
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetSettings() *ProjectSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *PaletteReport) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *PaletteReport) GetResults() []*ContrastResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LevelCounts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Aaa           int32                  `protobuf:"varint,1,opt,name=aaa,proto3" json:"aaa,omitempty"`
+	Aa            int32                  `protobuf:"varint,2,opt,name=aa,proto3" json:"aa,omitempty"`
+	AaLarge       int32                  `protobuf:"varint,3,opt,name=aa_large,json=aaLarge,proto3" json:"aa_large,omitempty"`
+	Fail          int32                  `protobuf:"varint,4,opt,name=fail,proto3" json:"fail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LevelCounts) Reset() {
+	*x = LevelCounts{}
+	mi := &file_contrast_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LevelCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LevelCounts) ProtoMessage() {}
+
+func (x *LevelCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LevelCounts.ProtoReflect.Descriptor instead.
+func (*LevelCounts) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LevelCounts) GetAaa() int32 {
+	if x != nil {
+		return x.Aaa
+	}
+	return 0
+}
+
+func (x *LevelCounts) GetAa() int32 {
+	if x != nil {
+		return x.Aa
+	}
+	return 0
+}
+
+func (x *LevelCounts) GetAaLarge() int32 {
+	if x != nil {
+		return x.AaLarge
+	}
+	return 0
+}
+
+func (x *LevelCounts) GetFail() int32 {
+	if x != nil {
+		return x.Fail
+	}
+	return 0
+}
+
+type UsageResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ForegroundName string                 `protobuf:"bytes,2,opt,name=foreground_name,json=foregroundName,proto3" json:"foreground_name,omitempty"`
+	ForegroundHex  string                 `protobuf:"bytes,3,opt,name=foreground_hex,json=foregroundHex,proto3" json:"foreground_hex,omitempty"`
+	BackgroundName string                 `protobuf:"bytes,4,opt,name=background_name,json=backgroundName,proto3" json:"background_name,omitempty"`
+	BackgroundHex  string                 `protobuf:"bytes,5,opt,name=background_hex,json=backgroundHex,proto3" json:"background_hex,omitempty"`
+	FontSizePx     float64                `protobuf:"fixed64,6,opt,name=font_size_px,json=fontSizePx,proto3" json:"font_size_px,omitempty"`
+	FontWeight     int32                  `protobuf:"varint,7,opt,name=font_weight,json=fontWeight,proto3" json:"font_weight,omitempty"`
+	Target         Target                 `protobuf:"varint,8,opt,name=target,proto3,enum=contrast.v1.Target" json:"target,omitempty"`
+	Algorithm      string                 `protobuf:"bytes,9,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Score          float64                `protobuf:"fixed64,10,opt,name=score,proto3" json:"score,omitempty"`
+	ScoreRaw       float64                `protobuf:"fixed64,11,opt,name=score_raw,json=scoreRaw,proto3" json:"score_raw,omitempty"`
+	Required       float64                `protobuf:"fixed64,12,opt,name=required,proto3" json:"required,omitempty"`
+	// Level is only set for WCAG.
+	Level         Level `protobuf:"varint,13,opt,name=level,proto3,enum=contrast.v1.Level" json:"level,omitempty"`
+	Pass          bool  `protobuf:"varint,14,opt,name=pass,proto3" json:"pass,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageResult) Reset() {
+	*x = UsageResult{}
+	mi := &file_contrast_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageResult) ProtoMessage() {}
+
+func (x *UsageResult) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageResult.ProtoReflect.Descriptor instead.
+func (*UsageResult) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UsageResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UsageResult) GetForegroundName() string {
+	if x != nil {
+		return x.ForegroundName
+	}
+	return ""
+}
+
+func (x *UsageResult) GetForegroundHex() string {
+	if x != nil {
+		return x.ForegroundHex
+	}
+	return ""
+}
+
+func (x *UsageResult) GetBackgroundName() string {
+	if x != nil {
+		return x.BackgroundName
+	}
+	return ""
+}
+
+func (x *UsageResult) GetBackgroundHex() string {
+	if x != nil {
+		return x.BackgroundHex
+	}
+	return ""
+}
+
+func (x *UsageResult) GetFontSizePx() float64 {
+	if x != nil {
+		return x.FontSizePx
+	}
+	return 0
+}
+
+func (x *UsageResult) GetFontWeight() int32 {
+	if x != nil {
+		return x.FontWeight
+	}
+	return 0
+}
+
+func (x *UsageResult) GetTarget() Target {
+	if x != nil {
+		return x.Target
+	}
+	return Target_TARGET_UNSPECIFIED
+}
+
+func (x *UsageResult) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *UsageResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *UsageResult) GetScoreRaw() float64 {
+	if x != nil {
+		return x.ScoreRaw
+	}
+	return 0
+}
+
+func (x *UsageResult) GetRequired() float64 {
+	if x != nil {
+		return x.Required
+	}
+	return 0
+}
+
+func (x *UsageResult) GetLevel() Level {
+	if x != nil {
+		return x.Level
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *UsageResult) GetPass() bool {
+	if x != nil {
+		return x.Pass
+	}
+	return false
+}
+
+type PaletteWarning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Theme         string                 `protobuf:"bytes,1,opt,name=theme,proto3" json:"theme,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaletteWarning) Reset() {
+	*x = PaletteWarning{}
+	mi := &file_contrast_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaletteWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaletteWarning) ProtoMessage() {}
+
+func (x *PaletteWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaletteWarning.ProtoReflect.Descriptor instead.
+func (*PaletteWarning) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PaletteWarning) GetTheme() string {
+	if x != nil {
+		return x.Theme
+	}
+	return ""
+}
+
+func (x *PaletteWarning) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PaletteWarning) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *PaletteWarning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ProjectSettings struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Palette          string                 `protobuf:"bytes,1,opt,name=palette,proto3" json:"palette,omitempty"`
+	Algorithm        string                 `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	MaxFailingUsages int32                  `protobuf:"varint,3,opt,name=max_failing_usages,json=maxFailingUsages,proto3" json:"max_failing_usages,omitempty"`
+	MaxFailPairs     *int32                 `protobuf:"varint,4,opt,name=max_fail_pairs,json=maxFailPairs,proto3,oneof" json:"max_fail_pairs,omitempty"`
+	AllowWarnings    bool                   `protobuf:"varint,5,opt,name=allow_warnings,json=allowWarnings,proto3" json:"allow_warnings,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ProjectSettings) Reset() {
+	*x = ProjectSettings{}
+	mi := &file_contrast_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectSettings) ProtoMessage() {}
+
+func (x *ProjectSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_contrast_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectSettings.ProtoReflect.Descriptor instead.
+func (*ProjectSettings) Descriptor() ([]byte, []int) {
+	return file_contrast_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProjectSettings) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *ProjectSettings) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *ProjectSettings) GetMaxFailingUsages() int32 {
+	if x != nil {
+		return x.MaxFailingUsages
+	}
+	return 0
+}
+
+func (x *ProjectSettings) GetMaxFailPairs() int32 {
+	if x != nil && x.MaxFailPairs != nil {
+		return *x.MaxFailPairs
+	}
+	return 0
+}
+
+func (x *ProjectSettings) GetAllowWarnings() bool {
+	if x != nil {
+		return x.AllowWarnings
+	}
+	return false
+}
+
+var File_contrast_proto protoreflect.FileDescriptor
+
+const file_contrast_proto_rawDesc = "" +
+	"\n" +
+	"\x0econtrast.proto\x12\vcontrast.v1\"^\n" +
+	"\fCheckRequest\x12\x1e\n" +
+	"\n" +
+	"foreground\x18\x01 \x01(\tR\n" +
+	"foreground\x12\x1e\n" +
+	"\n" +
+	"background\x18\x02 \x01(\tR\n" +
+	"background\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\"T\n" +
+	"\rCheckResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\x06result\x18\x02 \x01(\v2\x1b.contrast.v1.ContrastResultR\x06result\"\xa1\x05\n" +
+	"\x0eContrastResult\x12%\n" +
+	"\x0eforeground_hex\x18\x01 \x01(\tR\rforegroundHex\x12'\n" +
+	"\x0fforeground_name\x18\x02 \x01(\tR\x0eforegroundName\x12)\n" +
+	"\x10foreground_theme\x18\x03 \x01(\tR\x0fforegroundTheme\x12%\n" +
+	"\x0ebackground_hex\x18\x04 \x01(\tR\rbackgroundHex\x12'\n" +
+	"\x0fbackground_name\x18\x05 \x01(\tR\x0ebackgroundName\x12)\n" +
+	"\x10background_theme\x18\x06 \x01(\tR\x0fbackgroundTheme\x12%\n" +
+	"\x0econtrast_ratio\x18\a \x01(\x01R\rcontrastRatio\x12,\n" +
+	"\x12contrast_ratio_raw\x18\b \x01(\x01R\x10contrastRatioRaw\x12<\n" +
+	"\x10level_small_text\x18\t \x01(\x0e2\x12.contrast.v1.LevelR\x0elevelSmallText\x12<\n" +
+	"\x10level_large_text\x18\n" +
+	" \x01(\x0e2\x12.contrast.v1.LevelR\x0elevelLargeText\x128\n" +
+	"\x0elevel_non_text\x18\v \x01(\x0e2\x12.contrast.v1.LevelR\flevelNonText\x121\n" +
+	"\bcategory\x18\f \x01(\x0e2\x15.contrast.v1.CategoryR\bcategory\x128\n" +
+	"\bcriteria\x18\r \x03(\v2\x1c.contrast.v1.CriterionResultR\bcriteria\x12!\n" +
+	"\frequires_fix\x18\x0e \x01(\bR\vrequiresFix\"\xa0\x01\n" +
+	"\x0fCriterionResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12(\n" +
+	"\x05level\x18\x03 \x01(\x0e2\x12.contrast.v1.LevelR\x05level\x123\n" +
+	"\x06checks\x18\x05 \x03(\v2\x1b.contrast.v1.ThresholdCheckR\x06checksJ\x04\b\x04\x10\x05R\x04pass\"g\n" +
+	"\x0eThresholdCheck\x12+\n" +
+	"\x06target\x18\x01 \x01(\x0e2\x13.contrast.v1.TargetR\x06target\x12\x14\n" +
+	"\x05ratio\x18\x02 \x01(\x01R\x05ratio\x12\x12\n" +
+	"\x04pass\x18\x03 \x01(\bR\x04pass\"\x9b\x01\n" +
+	"\x17GetPaletteReportRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x18\n" +
+	"\apalette\x18\x02 \x01(\tR\apalette\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12\x1c\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x12\x14\n" +
+	"\x05query\x18\x05 \x01(\tR\x05query\"\x8c\x04\n" +
+	"\rPaletteReport\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x18\n" +
+	"\apalette\x18\x02 \x01(\tR\apalette\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12\x12\n" +
+	"\x04pass\x18\x04 \x01(\bR\x04pass\x12\x1a\n" +
+	"\bproblems\x18\x05 \x03(\tR\bproblems\x120\n" +
+	"\x06totals\x18\x06 \x01(\v2\x18.contrast.v1.LevelCountsR\x06totals\x12?\n" +
+	"\x0efailing_usages\x18\a \x03(\v2\x18.contrast.v1.UsageResultR\rfailingUsages\x127\n" +
+	"\bwarnings\x18\b \x03(\v2\x1b.contrast.v1.PaletteWarningR\bwarnings\x12B\n" +
+	"\x0eusage_warnings\x18\t \x03(\v2\x1b.contrast.v1.PaletteWarningR\rusageWarnings\x128\n" +
+	"\bsettings\x18\n" +
+	" \x01(\v2\x1c.contrast.v1.ProjectSettingsR\bsettings\x12\x1c\n" +
+	"\talgorithm\x18\v \x01(\tR\talgorithm\x125\n" +
+	"\aresults\x18\f \x03(\v2\x1b.contrast.v1.ContrastResultR\aresults\"^\n" +
+	"\vLevelCounts\x12\x10\n" +
+	"\x03aaa\x18\x01 \x01(\x05R\x03aaa\x12\x0e\n" +
+	"\x02aa\x18\x02 \x01(\x05R\x02aa\x12\x19\n" +
+	"\baa_large\x18\x03 \x01(\x05R\aaaLarge\x12\x12\n" +
+	"\x04fail\x18\x04 \x01(\x05R\x04fail\"\xdc\x03\n" +
+	"\vUsageResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12'\n" +
+	"\x0fforeground_name\x18\x02 \x01(\tR\x0eforegroundName\x12%\n" +
+	"\x0eforeground_hex\x18\x03 \x01(\tR\rforegroundHex\x12'\n" +
+	"\x0fbackground_name\x18\x04 \x01(\tR\x0ebackgroundName\x12%\n" +
+	"\x0ebackground_hex\x18\x05 \x01(\tR\rbackgroundHex\x12 \n" +
+	"\ffont_size_px\x18\x06 \x01(\x01R\n" +
+	"fontSizePx\x12\x1f\n" +
+	"\vfont_weight\x18\a \x01(\x05R\n" +
+	"fontWeight\x12+\n" +
+	"\x06target\x18\b \x01(\x0e2\x13.contrast.v1.TargetR\x06target\x12\x1c\n" +
+	"\talgorithm\x18\t \x01(\tR\talgorithm\x12\x14\n" +
+	"\x05score\x18\n" +
+	" \x01(\x01R\x05score\x12\x1b\n" +
+	"\tscore_raw\x18\v \x01(\x01R\bscoreRaw\x12\x1a\n" +
+	"\brequired\x18\f \x01(\x01R\brequired\x12(\n" +
+	"\x05level\x18\r \x01(\x0e2\x12.contrast.v1.LevelR\x05level\x12\x12\n" +
+	"\x04pass\x18\x0e \x01(\bR\x04pass\"j\n" +
+	"\x0ePaletteWarning\x12\x14\n" +
+	"\x05theme\x18\x01 \x01(\tR\x05theme\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\xdc\x01\n" +
+	"\x0fProjectSettings\x12\x18\n" +
+	"\apalette\x18\x01 \x01(\tR\apalette\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\x12,\n" +
+	"\x12max_failing_usages\x18\x03 \x01(\x05R\x10maxFailingUsages\x12)\n" +
+	"\x0emax_fail_pairs\x18\x04 \x01(\x05H\x00R\fmaxFailPairs\x88\x01\x01\x12%\n" +
+	"\x0eallow_warnings\x18\x05 \x01(\bR\rallowWarningsB\x11\n" +
+	"\x0f_max_fail_pairs*K\n" +
+	"\x05Level\x12\x15\n" +
+	"\x11LEVEL_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"LEVEL_FAIL\x10\x01\x12\f\n" +
+	"\bLEVEL_AA\x10\x02\x12\r\n" +
+	"\tLEVEL_AAA\x10\x03*q\n" +
+	"\bCategory\x12\x18\n" +
+	"\x14CATEGORY_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rCATEGORY_FAIL\x10\x01\x12\x15\n" +
+	"\x11CATEGORY_AA_LARGE\x10\x02\x12\x0f\n" +
+	"\vCATEGORY_AA\x10\x03\x12\x10\n" +
+	"\fCATEGORY_AAA\x10\x04*c\n" +
+	"\x06Target\x12\x16\n" +
+	"\x12TARGET_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11TARGET_SMALL_TEXT\x10\x01\x12\x15\n" +
+	"\x11TARGET_LARGE_TEXT\x10\x02\x12\x13\n" +
+	"\x0fTARGET_NON_TEXT\x10\x032\xf0\x01\n" +
+	"\x0fContrastService\x12>\n" +
+	"\x05Check\x12\x19.contrast.v1.CheckRequest\x1a\x1a.contrast.v1.CheckResponse\x12G\n" +
+	"\n" +
+	"BatchCheck\x12\x19.contrast.v1.CheckRequest\x1a\x1a.contrast.v1.CheckResponse(\x010\x01\x12T\n" +
+	"\x10GetPaletteReport\x12$.contrast.v1.GetPaletteReportRequest\x1a\x1a.contrast.v1.PaletteReportB'Z%karan-contrast-checker-api/contrastpbb\x06proto3"
+
+var (
+	file_contrast_proto_rawDescOnce sync.Once
+	file_contrast_proto_rawDescData []byte
+)
+
+func file_contrast_proto_rawDescGZIP() []byte {
+	file_contrast_proto_rawDescOnce.Do(func() {
+		file_contrast_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_contrast_proto_rawDesc), len(file_contrast_proto_rawDesc)))
+	})
+	return file_contrast_proto_rawDescData
+}
+
+var file_contrast_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_contrast_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_contrast_proto_goTypes = []any{
+	(Level)(0),                      // 0: contrast.v1.Level
+	(Category)(0),                   // 1: contrast.v1.Category
+	(Target)(0),                     // 2: contrast.v1.Target
+	(*CheckRequest)(nil),            // 3: contrast.v1.CheckRequest
+	(*CheckResponse)(nil),           // 4: contrast.v1.CheckResponse
+	(*ContrastResult)(nil),          // 5: contrast.v1.ContrastResult
+	(*CriterionResult)(nil),         // 6: contrast.v1.CriterionResult
+	(*ThresholdCheck)(nil),          // 7: contrast.v1.ThresholdCheck
+	(*GetPaletteReportRequest)(nil), // 8: contrast.v1.GetPaletteReportRequest
+	(*PaletteReport)(nil),           // 9: contrast.v1.PaletteReport
+	(*LevelCounts)(nil),             // 10: contrast.v1.LevelCounts
+	(*UsageResult)(nil),             // 11: contrast.v1.UsageResult
+	(*PaletteWarning)(nil),          // 12: contrast.v1.PaletteWarning
+	(*ProjectSettings)(nil),         // 13: contrast.v1.ProjectSettings
+}
+var file_contrast_proto_depIdxs = []int32{
+	5,  // 0: contrast.v1.CheckResponse.result:type_name -> contrast.v1.ContrastResult
+	0,  // 1: contrast.v1.ContrastResult.level_small_text:type_name -> contrast.v1.Level
+	0,  // 2: contrast.v1.ContrastResult.level_large_text:type_name -> contrast.v1.Level
+	0,  // 3: contrast.v1.ContrastResult.level_non_text:type_name -> contrast.v1.Level
+	1,  // 4: contrast.v1.ContrastResult.category:type_name -> contrast.v1.Category
+	6,  // 5: contrast.v1.ContrastResult.criteria:type_name -> contrast.v1.CriterionResult
+	0,  // 6: contrast.v1.CriterionResult.level:type_name -> contrast.v1.Level
+	7,  // 7: contrast.v1.CriterionResult.checks:type_name -> contrast.v1.ThresholdCheck
+	2,  // 8: contrast.v1.ThresholdCheck.target:type_name -> contrast.v1.Target
+	10, // 9: contrast.v1.PaletteReport.totals:type_name -> contrast.v1.LevelCounts
+	11, // 10: contrast.v1.PaletteReport.failing_usages:type_name -> contrast.v1.UsageResult
+	12, // 11: contrast.v1.PaletteReport.warnings:type_name -> contrast.v1.PaletteWarning
+	12, // 12: contrast.v1.PaletteReport.usage_warnings:type_name -> contrast.v1.PaletteWarning
+	13, // 13: contrast.v1.PaletteReport.settings:type_name -> contrast.v1.ProjectSettings
+	5,  // 14: contrast.v1.PaletteReport.results:type_name -> contrast.v1.ContrastResult
+	2,  // 15: contrast.v1.UsageResult.target:type_name -> contrast.v1.Target
+	0,  // 16: contrast.v1.UsageResult.level:type_name -> contrast.v1.Level
+	3,  // 17: contrast.v1.ContrastService.Check:input_type -> contrast.v1.CheckRequest
+	3,  // 18: contrast.v1.ContrastService.BatchCheck:input_type -> contrast.v1.CheckRequest
+	8,  // 19: contrast.v1.ContrastService.GetPaletteReport:input_type -> contrast.v1.GetPaletteReportRequest
+	4,  // 20: contrast.v1.ContrastService.Check:output_type -> contrast.v1.CheckResponse
+	4,  // 21: contrast.v1.ContrastService.BatchCheck:output_type -> contrast.v1.CheckResponse
+	9,  // 22: contrast.v1.ContrastService.GetPaletteReport:output_type -> contrast.v1.PaletteReport
+	20, // [20:23] is the sub-list for method output_type
+	17, // [17:20] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_contrast_proto_init() }
+func file_contrast_proto_init() {
+	if File_contrast_proto != nil {
+		return
+	}
+	file_contrast_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_contrast_proto_rawDesc), len(file_contrast_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_contrast_proto_goTypes,
+		DependencyIndexes: file_contrast_proto_depIdxs,
+		EnumInfos:         file_contrast_proto_enumTypes,
+		MessageInfos:      file_contrast_proto_msgTypes,
+	}.Build()
+	File_contrast_proto = out.File
+	file_contrast_proto_goTypes = nil
+	file_contrast_proto_depIdxs = nil
+}
@@ -0,0 +1,216 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: contrast.proto
+
+// The gRPC interface of the contrast checker. Its messages carry the same
+// values as the JSON API: ContrastResult matches the JSON ContrastResult and
+// PaletteReport the JSON ProjectCheck.
+
+package contrastpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ContrastService_Check_FullMethodName            = "/contrast.v1.ContrastService/Check"
+	ContrastService_BatchCheck_FullMethodName       = "/contrast.v1.ContrastService/BatchCheck"
+	ContrastService_GetPaletteReport_FullMethodName = "/contrast.v1.ContrastService/GetPaletteReport"
+)
+
+// ContrastServiceClient is the client API for ContrastService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ContrastService evaluates colors with the engine behind the HTTP server.
+// When the server runs with -auth, calls need the viewer role and carry
+// their credentials in the authorization metadata, as over HTTP.
+type ContrastServiceClient interface {
+	// Check classifies one foreground on one background.
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// BatchCheck classifies every pair sent, answering each in the order
+	// received. The stream ends with INVALID_ARGUMENT at the first pair
+	// whose colors do not parse.
+	BatchCheck(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckRequest, CheckResponse], error)
+	// GetPaletteReport holds a palette to its project's thresholds, as
+	// /api/check does, and lists its pairs.
+	GetPaletteReport(ctx context.Context, in *GetPaletteReportRequest, opts ...grpc.CallOption) (*PaletteReport, error)
+}
+
+type contrastServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContrastServiceClient(cc grpc.ClientConnInterface) ContrastServiceClient {
+	return &contrastServiceClient{cc}
+}
+
+func (c *contrastServiceClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, ContrastService_Check_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contrastServiceClient) BatchCheck(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckRequest, CheckResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContrastService_ServiceDesc.Streams[0], ContrastService_BatchCheck_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CheckRequest, CheckResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContrastService_BatchCheckClient = grpc.BidiStreamingClient[CheckRequest, CheckResponse]
+
+func (c *contrastServiceClient) GetPaletteReport(ctx context.Context, in *GetPaletteReportRequest, opts ...grpc.CallOption) (*PaletteReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaletteReport)
+	err := c.cc.Invoke(ctx, ContrastService_GetPaletteReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContrastServiceServer is the server API for ContrastService service.
+// All implementations must embed UnimplementedContrastServiceServer
+// for forward compatibility.
+//
+// ContrastService evaluates colors with the engine behind the HTTP server.
+// When the server runs with -auth, calls need the viewer role and carry
+// their credentials in the authorization metadata, as over HTTP.
+type ContrastServiceServer interface {
+	// Check classifies one foreground on one background.
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	// BatchCheck classifies every pair sent, answering each in the order
+	// received. The stream ends with INVALID_ARGUMENT at the first pair
+	// whose colors do not parse.
+	BatchCheck(grpc.BidiStreamingServer[CheckRequest, CheckResponse]) error
+	// GetPaletteReport holds a palette to its project's thresholds, as
+	// /api/check does, and lists its pairs.
+	GetPaletteReport(context.Context, *GetPaletteReportRequest) (*PaletteReport, error)
+	mustEmbedUnimplementedContrastServiceServer()
+}
+
+// UnimplementedContrastServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedContrastServiceServer struct{}
+
+func (UnimplementedContrastServiceServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedContrastServiceServer) BatchCheck(grpc.BidiStreamingServer[CheckRequest, CheckResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchCheck not implemented")
+}
+func (UnimplementedContrastServiceServer) GetPaletteReport(context.Context, *GetPaletteReportRequest) (*PaletteReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaletteReport not implemented")
+}
+func (UnimplementedContrastServiceServer) mustEmbedUnimplementedContrastServiceServer() {}
+func (UnimplementedContrastServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeContrastServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContrastServiceServer will
+// result in compilation errors.
+type UnsafeContrastServiceServer interface {
+	mustEmbedUnimplementedContrastServiceServer()
+}
+
+func RegisterContrastServiceServer(s grpc.ServiceRegistrar, srv ContrastServiceServer) {
+	// If the following call pancis, it indicates UnimplementedContrastServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ContrastService_ServiceDesc, srv)
+}
+
+func _ContrastService_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContrastServiceServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContrastService_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContrastServiceServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContrastService_BatchCheck_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContrastServiceServer).BatchCheck(&grpc.GenericServerStream[CheckRequest, CheckResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContrastService_BatchCheckServer = grpc.BidiStreamingServer[CheckRequest, CheckResponse]
+
+func _ContrastService_GetPaletteReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaletteReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContrastServiceServer).GetPaletteReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContrastService_GetPaletteReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContrastServiceServer).GetPaletteReport(ctx, req.(*GetPaletteReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ContrastService_ServiceDesc is the grpc.ServiceDesc for ContrastService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ContrastService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "contrast.v1.ContrastService",
+	HandlerType: (*ContrastServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _ContrastService_Check_Handler,
+		},
+		{
+			MethodName: "GetPaletteReport",
+			Handler:    _ContrastService_GetPaletteReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchCheck",
+			Handler:       _ContrastService_BatchCheck_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "contrast.proto",
+}
@@ -0,0 +1,5 @@
+// Package contrastpb holds the protocol buffer messages and gRPC stubs of
+// the contrast checker's gRPC service, generated from contrast.proto.
+package contrastpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative contrast.proto
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cssColor is an sRGB color with alpha. Channels are 0-255 and A is 0-1.
+type cssColor struct {
+	R, G, B, A float64
+}
+
+var (
+	cssBlack = cssColor{0, 0, 0, 1}
+	cssWhite = cssColor{255, 255, 255, 1}
+)
+
+// over composites c on top of the opaque color bg.
+func (c cssColor) over(bg cssColor) cssColor {
+	return cssColor{
+		R: c.R*c.A + bg.R*(1-c.A),
+		G: c.G*c.A + bg.G*(1-c.A),
+		B: c.B*c.A + bg.B*(1-c.A),
+		A: 1,
+	}
+}
+
+func (c cssColor) hex() string {
+	ch := func(v float64) int { return int(math.Round(math.Max(0, math.Min(255, v)))) }
+	return fmt.Sprintf("#%02x%02x%02x", ch(c.R), ch(c.G), ch(c.B))
+}
+
+// parseCSSColor parses hex, rgb(), rgba(), hsl(), hsla(), named colors, and
+// transparent. currentcolor and the CSS-wide keywords are left to the caller.
+func parseCSSColor(s string) (cssColor, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "transparent" {
+		return cssColor{}, true
+	}
+	if hex, ok := cssNamedColors[s]; ok {
+		s = hex
+	}
+	if strings.HasPrefix(s, "#") {
+		h := s[1:]
+		if len(h) == 3 || len(h) == 4 {
+			var b strings.Builder
+			for _, r := range h {
+				b.WriteRune(r)
+				b.WriteRune(r)
+			}
+			h = b.String()
+		}
+		if len(h) != 6 && len(h) != 8 {
+			return cssColor{}, false
+		}
+		var v [4]float64
+		v[3] = 255
+		for i := 0; i < len(h)/2; i++ {
+			n, err := strconv.ParseUint(h[i*2:i*2+2], 16, 8)
+			if err != nil {
+				return cssColor{}, false
+			}
+			v[i] = float64(n)
+		}
+		return cssColor{v[0], v[1], v[2], v[3] / 255}, true
+	}
+
+	name, args, ok := cssFunction(s)
+	if !ok {
+		return cssColor{}, false
+	}
+	// Accept both "rgb(1, 2, 3, 0.5)" and "rgb(1 2 3 / 50%)".
+	args = strings.NewReplacer(",", " ", "/", " ").Replace(args)
+	parts := strings.Fields(args)
+	if len(parts) != 3 && len(parts) != 4 {
+		return cssColor{}, false
+	}
+	alpha := 1.0
+	if len(parts) == 4 {
+		a, ok := cssNumber(parts[3], 1)
+		if !ok {
+			return cssColor{}, false
+		}
+		alpha = math.Max(0, math.Min(1, a))
+	}
+	switch name {
+	case "rgb", "rgba":
+		var v [3]float64
+		for i := range v {
+			n, ok := cssNumber(parts[i], 255)
+			if !ok {
+				return cssColor{}, false
+			}
+			v[i] = n
+		}
+		return cssColor{v[0], v[1], v[2], alpha}, true
+	case "hsl", "hsla":
+		h, err := strconv.ParseFloat(strings.TrimSuffix(parts[0], "deg"), 64)
+		if err != nil {
+			return cssColor{}, false
+		}
+		sat, ok1 := cssNumber(parts[1], 1)
+		light, ok2 := cssNumber(parts[2], 1)
+		if !ok1 || !ok2 || !strings.HasSuffix(parts[1], "%") || !strings.HasSuffix(parts[2], "%") {
+			return cssColor{}, false
+		}
+		r, g, b := hslToRGB(h, sat, light)
+		return cssColor{r * 255, g * 255, b * 255, alpha}, true
+	}
+	return cssColor{}, false
+}
+
+// cssNumber parses a number or a percentage of scale.
+func cssNumber(s string, scale float64) (float64, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(p, 64)
+		return v / 100 * scale, err == nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	f := func(n float64) float64 {
+		k := math.Mod(n+h*12, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return f(0), f(8), f(4)
+}
+
+// cssFunction splits "name(args)".
+func cssFunction(s string) (name, args string, ok bool) {
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:open]), s[open+1 : len(s)-1], true
+}
+
+// cssDecl is one declaration together with where it was written.
+type cssDecl struct {
+	Prop      string
+	Value     string
+	Important bool
+	// Source is "file:line" of the declaration's rule, or "file:line
+	// (inline)" for a style attribute.
+	Source string
+}
+
+// cssRule is a style rule. Order is its position in the cascade.
+type cssRule struct {
+	Selectors []cssSelector
+	Decls     []cssDecl
+	Order     int
+}
+
+// stripCSSComments blanks out comments, keeping newlines so that offsets
+// still map to the right lines.
+func stripCSSComments(src string) string {
+	b := []byte(src)
+	for i := 0; i+1 < len(b); i++ {
+		if b[i] != '/' || b[i+1] != '*' {
+			continue
+		}
+		end := len(b)
+		if j := strings.Index(src[i+2:], "*/"); j >= 0 {
+			end = i + 2 + j + 2
+		}
+		for k := i; k < end; k++ {
+			if b[k] != '\n' {
+				b[k] = ' '
+			}
+		}
+		i = end - 1
+	}
+	return string(b)
+}
+
+// matchingBrace returns the index of the "}" closing the block that starts
+// after offset start, or len(s) if it is not closed.
+func matchingBrace(s string, start int) int {
+	depth := 1
+	var quote byte
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// parseCSS parses the style rules of a stylesheet. Rules inside @media,
+// @supports, and @layer blocks are kept as if their conditions held; other
+// at-rules are skipped. file and firstLine label the declarations.
+func parseCSS(src, file string, firstLine int) []cssRule {
+	src = stripCSSComments(src)
+	var rules []cssRule
+	var walk func(start, end int)
+	walk = func(start, end int) {
+		for i := start; i < end; {
+			next := strings.IndexAny(src[i:end], "{;}")
+			if next < 0 {
+				return
+			}
+			next += i
+			prelude := strings.TrimSpace(src[i:next])
+			if src[next] != '{' {
+				i = next + 1
+				continue
+			}
+			close := matchingBrace(src, next+1)
+			if close > end {
+				close = end
+			}
+			lead := len(src[i:next]) - len(strings.TrimLeft(src[i:next], " \t\r\n"))
+			line := firstLine + strings.Count(src[:i+lead], "\n")
+			if strings.HasPrefix(prelude, "@") {
+				at := strings.ToLower(strings.Fields(prelude)[0])
+				if at == "@media" || at == "@supports" || at == "@layer" {
+					walk(next+1, close)
+				}
+			} else if selectors, ok := parseSelectorList(prelude); ok {
+				source := fmt.Sprintf("%s:%d", file, line)
+				rules = append(rules, cssRule{Selectors: selectors, Decls: parseDecls(src[next+1:close], source)})
+			}
+			i = close + 1
+		}
+	}
+	walk(0, len(src))
+	return rules
+}
+
+// parseDecls parses the declarations of a rule or style attribute.
+func parseDecls(block, source string) []cssDecl {
+	var decls []cssDecl
+	depth := 0
+	var quote byte
+	start := 0
+	flush := func(end int) {
+		prop, value, ok := strings.Cut(block[start:end], ":")
+		start = end + 1
+		if !ok {
+			return
+		}
+		prop = strings.TrimSpace(prop)
+		if !strings.HasPrefix(prop, "--") {
+			prop = strings.ToLower(prop)
+		}
+		value = strings.TrimSpace(value)
+		important := false
+		if i := strings.LastIndex(value, "!"); i >= 0 && strings.EqualFold(strings.TrimSpace(value[i+1:]), "important") {
+			important = true
+			value = strings.TrimSpace(value[:i])
+		}
+		if prop != "" {
+			decls = append(decls, cssDecl{Prop: prop, Value: value, Important: important, Source: source})
+		}
+	}
+	for i := 0; i < len(block); i++ {
+		switch c := block[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ';' && depth == 0:
+			flush(i)
+		}
+	}
+	flush(len(block))
+	return decls
+}
+
+// cssCompound is a compound selector such as "p.note#intro[lang]".
+type cssCompound struct {
+	Tag     string
+	ID      string
+	Classes []string
+	Attrs   []cssAttr
+	// Pseudo lists the structural pseudo-classes root, first-child, and
+	// last-child.
+	Pseudo []string
+}
+
+type cssAttr struct {
+	Name, Op, Value string
+}
+
+// cssSelector is a complex selector. Combinators[i] joins Parts[i] and
+// Parts[i+1] and is one of ' ', '>', '+', or '~'.
+type cssSelector struct {
+	Parts       []cssCompound
+	Combinators []byte
+	Specificity [3]int
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// parseSelectorList parses a comma-separated selector list. Selectors that
+// can only match in some interactive state (:hover, :focus, and the like)
+// or match generated content (::before) are dropped, because they do not
+// apply to the page as it is first shown.
+func parseSelectorList(s string) ([]cssSelector, bool) {
+	var out []cssSelector
+	for _, part := range strings.Split(s, ",") {
+		if sel, ok := parseSelector(strings.TrimSpace(part)); ok {
+			out = append(out, sel)
+		}
+	}
+	return out, len(out) > 0
+}
+
+func parseSelector(s string) (cssSelector, bool) {
+	var sel cssSelector
+	cur := cssCompound{}
+	empty := true
+	pendingComb := byte(0)
+	push := func() {
+		if !empty {
+			if len(sel.Parts) > 0 {
+				if pendingComb == 0 {
+					pendingComb = ' '
+				}
+				sel.Combinators = append(sel.Combinators, pendingComb)
+			}
+			sel.Parts = append(sel.Parts, cur)
+		}
+		cur, empty, pendingComb = cssCompound{}, true, 0
+	}
+	name := func(i int) (string, int) {
+		j := i
+		for j < len(s) && isNameChar(s[j]) {
+			j++
+		}
+		return s[i:j], j
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if !empty {
+				push()
+				pendingComb = ' '
+			}
+			i++
+		case c == '>' || c == '+' || c == '~':
+			if !empty {
+				push()
+			}
+			if len(sel.Parts) == 0 {
+				return sel, false
+			}
+			pendingComb = c
+			i++
+		case c == '*':
+			empty = false
+			i++
+		case c == '#':
+			var n string
+			n, i = name(i + 1)
+			cur.ID, empty = n, false
+			sel.Specificity[0]++
+		case c == '.':
+			var n string
+			n, i = name(i + 1)
+			cur.Classes, empty = append(cur.Classes, n), false
+			sel.Specificity[1]++
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return sel, false
+			}
+			cur.Attrs, empty = append(cur.Attrs, parseAttrSelector(s[i+1:i+end])), false
+			sel.Specificity[1]++
+			i += end + 1
+		case c == ':':
+			if strings.HasPrefix(s[i:], "::") {
+				return sel, false
+			}
+			var n string
+			n, i = name(i + 1)
+			n = strings.ToLower(n)
+			switch n {
+			case "root", "first-child", "last-child":
+				cur.Pseudo, empty = append(cur.Pseudo, n), false
+				sel.Specificity[1]++
+			default:
+				return sel, false
+			}
+			if i < len(s) && s[i] == '(' {
+				return sel, false
+			}
+		case isNameChar(c):
+			var n string
+			n, i = name(i)
+			cur.Tag, empty = strings.ToLower(n), false
+			sel.Specificity[2]++
+		default:
+			return sel, false
+		}
+	}
+	if empty && pendingComb != 0 && pendingComb != ' ' {
+		return sel, false
+	}
+	push()
+	return sel, len(sel.Parts) > 0
+}
+
+func parseAttrSelector(s string) cssAttr {
+	for _, op := range []string{"~=", "|=", "^=", "$=", "*=", "="} {
+		if n, v, ok := strings.Cut(s, op); ok {
+			return cssAttr{
+				Name:  strings.ToLower(strings.TrimSpace(n)),
+				Op:    op,
+				Value: strings.Trim(strings.TrimSpace(v), `"'`),
+			}
+		}
+	}
+	return cssAttr{Name: strings.ToLower(strings.TrimSpace(s))}
+}
+
+func (a cssAttr) matches(e *htmlElement) bool {
+	v, ok := e.Attrs[a.Name]
+	if !ok {
+		return false
+	}
+	switch a.Op {
+	case "":
+		return true
+	case "=":
+		return v == a.Value
+	case "~=":
+		for _, f := range strings.Fields(v) {
+			if f == a.Value {
+				return true
+			}
+		}
+		return false
+	case "|=":
+		return v == a.Value || strings.HasPrefix(v, a.Value+"-")
+	case "^=":
+		return a.Value != "" && strings.HasPrefix(v, a.Value)
+	case "$=":
+		return a.Value != "" && strings.HasSuffix(v, a.Value)
+	case "*=":
+		return a.Value != "" && strings.Contains(v, a.Value)
+	}
+	return false
+}
+
+func (c cssCompound) matches(e *htmlElement) bool {
+	if c.Tag != "" && c.Tag != e.Tag {
+		return false
+	}
+	if c.ID != "" && e.Attrs["id"] != c.ID {
+		return false
+	}
+	for _, class := range c.Classes {
+		if !e.hasClass(class) {
+			return false
+		}
+	}
+	for _, a := range c.Attrs {
+		if !a.matches(e) {
+			return false
+		}
+	}
+	for _, p := range c.Pseudo {
+		switch p {
+		case "root":
+			if e.Tag != "html" {
+				return false
+			}
+		case "first-child":
+			if e.Parent == nil || e.Parent.Children[0] != e {
+				return false
+			}
+		case "last-child":
+			if e.Parent == nil || e.Parent.Children[len(e.Parent.Children)-1] != e {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matches reports whether the selector matches e, working from the
+// rightmost compound outwards.
+func (s cssSelector) matches(e *htmlElement) bool {
+	return s.matchFrom(len(s.Parts)-1, e)
+}
+
+func (s cssSelector) matchFrom(i int, e *htmlElement) bool {
+	if e == nil || !s.Parts[i].matches(e) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch s.Combinators[i-1] {
+	case '>':
+		return s.matchFrom(i-1, e.Parent)
+	case '+':
+		return s.matchFrom(i-1, e.previousSibling())
+	case '~':
+		for p := e.previousSibling(); p != nil; p = p.previousSibling() {
+			if s.matchFrom(i-1, p) {
+				return true
+			}
+		}
+		return false
+	default:
+		for p := e.Parent; p != nil; p = p.Parent {
+			if s.matchFrom(i-1, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// compareSpecificity orders specificities like strings.Compare.
+func compareSpecificity(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"karan-contrast-checker-api/contrastpb"
+)
+
+// contrastService serves the gRPC ContrastService with the same engine as
+// the JSON API.
+type contrastService struct {
+	contrastpb.UnimplementedContrastServiceServer
+}
+
+func (contrastService) Check(ctx context.Context, req *contrastpb.CheckRequest) (*contrastpb.CheckResponse, error) {
+	result, err := checkPair(req.GetForeground(), req.GetBackground())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &contrastpb.CheckResponse{Id: req.GetId(), Result: protoResult(result)}, nil
+}
+
+func (contrastService) BatchCheck(stream grpc.BidiStreamingServer[contrastpb.CheckRequest, contrastpb.CheckResponse]) error {
+	for n := 1; ; n++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result, err := checkPair(req.GetForeground(), req.GetBackground())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "pair %d: %v", n, err)
+		}
+		if err := stream.Send(&contrastpb.CheckResponse{Id: req.GetId(), Result: protoResult(result)}); err != nil {
+			return err
+		}
+	}
+}
+
+func (contrastService) GetPaletteReport(ctx context.Context, req *contrastpb.GetPaletteReportRequest) (*contrastpb.PaletteReport, error) {
+	project, err := findProject(ctx, req.GetProject())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	var version string
+	if req.GetVersion() != 0 {
+		version = strconv.Itoa(int(req.GetVersion()))
+	}
+	palette, err := findPalette(ctx, project, req.GetPalette(), version)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	q, err := projectResultQuery(url.Values{"q": {req.GetQuery()}, "algorithm": {req.GetAlgorithm()}}, project)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	check := checkProject(ctx, project, palette, q.Algorithm)
+	report := &contrastpb.PaletteReport{
+		Project:       check.Project,
+		Palette:       check.Palette,
+		Version:       int32(check.Version),
+		Pass:          check.Pass,
+		Problems:      check.Problems,
+		Totals:        protoLevelCounts(check.Totals),
+		Warnings:      protoWarnings(check.Warnings),
+		UsageWarnings: protoWarnings(check.UsageWarnings),
+		Algorithm:     check.Algorithm,
+		Settings: &contrastpb.ProjectSettings{
+			Palette:          check.Settings.Palette,
+			Algorithm:        check.Settings.Algorithm,
+			MaxFailingUsages: int32(check.Settings.MaxFailingUsages),
+			AllowWarnings:    check.Settings.AllowWarnings,
+		},
+	}
+	if n := check.Settings.MaxFailPairs; n != nil {
+		report.Settings.MaxFailPairs = new(int32)
+		*report.Settings.MaxFailPairs = int32(*n)
+	}
+	for _, u := range check.FailingUsages {
+		report.FailingUsages = append(report.FailingUsages, protoUsage(u))
+	}
+	for _, r := range allResults(ctx, &palette.ColorSets, q).Results {
+		report.Results = append(report.Results, protoResult(r))
+	}
+	return report, nil
+}
+
+// grpcError is the status for an error from findProject or findPalette.
+func grpcError(err error) error {
+	if errors.Is(err, errNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// protoLevel relies on Level and contrastpb.Level listing the levels in
+// the same order, after LEVEL_UNSPECIFIED.
+func protoLevel(l Level) contrastpb.Level {
+	return contrastpb.Level(l + 1)
+}
+
+// protoCategory relies on the same ordering as protoLevel.
+func protoCategory(c Category) contrastpb.Category {
+	return contrastpb.Category(c + 1)
+}
+
+func protoTarget(t Target) contrastpb.Target {
+	switch t {
+	case TargetSmallText:
+		return contrastpb.Target_TARGET_SMALL_TEXT
+	case TargetLargeText:
+		return contrastpb.Target_TARGET_LARGE_TEXT
+	case TargetNonText:
+		return contrastpb.Target_TARGET_NON_TEXT
+	default:
+		return contrastpb.Target_TARGET_UNSPECIFIED
+	}
+}
+
+func protoResult(r ContrastResult) *contrastpb.ContrastResult {
+	p := &contrastpb.ContrastResult{
+		ForegroundHex:    r.ForegroundHex,
+		ForegroundName:   r.ForegroundName,
+		ForegroundTheme:  r.ForegroundTheme,
+		BackgroundHex:    r.BackgroundHex,
+		BackgroundName:   r.BackgroundName,
+		BackgroundTheme:  r.BackgroundTheme,
+		ContrastRatio:    r.ContrastRatio,
+		ContrastRatioRaw: r.ContrastRatioRaw,
+		LevelSmallText:   protoLevel(r.LevelSmallText),
+		LevelLargeText:   protoLevel(r.LevelLargeText),
+		LevelNonText:     protoLevel(r.LevelNonText),
+		Category:         protoCategory(r.Category),
+		RequiresFix:      r.RequiresFix,
+	}
+	for _, c := range r.Criteria {
+		criterion := &contrastpb.CriterionResult{Id: c.ID, Name: c.Name, Level: protoLevel(c.Level)}
+		for _, check := range c.Checks {
+			criterion.Checks = append(criterion.Checks, &contrastpb.ThresholdCheck{Target: protoTarget(check.Target), Ratio: check.Ratio, Pass: check.Pass})
+		}
+		p.Criteria = append(p.Criteria, criterion)
+	}
+	return p
+}
+
+func protoUsage(u UsageResult) *contrastpb.UsageResult {
+	p := &contrastpb.UsageResult{
+		Name:           u.Name,
+		ForegroundName: u.ForegroundName,
+		ForegroundHex:  u.ForegroundHex,
+		BackgroundName: u.BackgroundName,
+		BackgroundHex:  u.BackgroundHex,
+		FontSizePx:     u.FontSizePx,
+		FontWeight:     int32(u.FontWeight),
+		Target:         protoTarget(u.Target),
+		Algorithm:      u.Algorithm,
+		Score:          u.Score,
+		ScoreRaw:       u.ScoreRaw,
+		Required:       u.Required,
+		Pass:           u.Pass,
+	}
+	if u.Level != nil {
+		p.Level = protoLevel(*u.Level)
+	}
+	return p
+}
+
+func protoWarnings(warnings []PaletteWarning) []*contrastpb.PaletteWarning {
+	var p []*contrastpb.PaletteWarning
+	for _, w := range warnings {
+		p = append(p, &contrastpb.PaletteWarning{Theme: w.Theme, Name: w.Name, Value: w.Value, Message: w.Message})
+	}
+	return p
+}
+
+func protoLevelCounts(c LevelCounts) *contrastpb.LevelCounts {
+	return &contrastpb.LevelCounts{Aaa: int32(c.AAA), Aa: int32(c.AA), AaLarge: int32(c.AALarge), Fail: int32(c.Fail)}
+}
+
+// grpcAuthorize holds ContrastService calls to the viewer role, reading
+// credentials from the authorization metadata as the HTTP server reads the
+// Authorization header. Health checks and reflection stay open, so that
+// load balancers and tools need no credentials.
+func grpcAuthorize(ctx context.Context, method string) error {
+	if auth == nil || !strings.HasPrefix(method, "/"+contrastpb.ContrastService_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		r.Header.Add("Authorization", v)
+	}
+
+	p, err := auth.authenticate(r)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if p.Role < RoleViewer {
+		if p.Method == "anonymous" {
+			return status.Error(codes.Unauthenticated, "sign in to continue")
+		}
+		return status.Errorf(codes.PermissionDenied, "%s is a %s; %s needs %s", p.Name, p.Role, method, RoleViewer)
+	}
+	return nil
+}
+
+// grpcCall tags a call with an ID (reusing x-request-id when the client
+// sends one), authorizes it, and logs it once it has been served, like
+// withRequestLogging and authorize do for HTTP.
+func grpcCall(ctx context.Context, method string, serve func(context.Context) error) error {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-request-id")) > 0 {
+		id = md.Get("x-request-id")[0]
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+	ctx = context.WithValue(ctx, requestIDKey, id)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+
+	start := time.Now()
+	err := grpcAuthorize(ctx, method)
+	if err == nil {
+		err = serve(ctx)
+	}
+	slog.InfoContext(ctx, "call served",
+		"method", method,
+		"code", status.Code(err),
+		"duration", time.Since(start),
+	)
+	return err
+}
+
+// contextStream is a server stream with the context of grpcCall.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context { return s.ctx }
+
+// serveGRPC serves ContrastService, health checking, and reflection on
+// addr until the listener fails.
+func serveGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			var resp any
+			err := grpcCall(ctx, info.FullMethod, func(ctx context.Context) error {
+				var err error
+				resp, err = handler(ctx, req)
+				return err
+			})
+			return resp, err
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return grpcCall(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+				return handler(srv, contextStream{ss, ctx})
+			})
+		}),
+	)
+	contrastpb.RegisterContrastServiceServer(s, contrastService{})
+
+	h := health.NewServer()
+	h.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	h.SetServingStatus(contrastpb.ContrastService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, h)
+	reflection.Register(s)
+
+	fmt.Printf("gRPC service running at %s\n", lis.Addr())
+	return s.Serve(lis)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultLocale = "en"
+
+// localeAliases maps tags used by earlier versions of the UI to catalog names.
+var localeAliases = map[string]string{
+	"jp": "ja",
+}
+
+// pluralRules picks the CLDR plural category for n. Languages that are not
+// listed use the English rule.
+var pluralRules = map[string]func(n int) string{
+	"en": func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"ja": func(int) string { return "other" },
+	"ko": func(int) string { return "other" },
+	"zh": func(int) string { return "other" },
+}
+
+var catalogs map[string]*Localizer
+
+// message holds the text for each plural category. In a catalog file a
+// message is either a plain string or an object such as
+// {"one": "{count} result", "other": "{count} results"}.
+type message map[string]string
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = message{"other": s}
+		return nil
+	}
+	var forms map[string]string
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	if _, ok := forms["other"]; !ok {
+		return fmt.Errorf("plural message is missing the \"other\" form")
+	}
+	*m = message(forms)
+	return nil
+}
+
+// Localizer looks up messages for one locale, falling back to the default
+// locale and finally to the key itself.
+type Localizer struct {
+	Lang     string
+	messages map[string]message
+	fallback *Localizer
+}
+
+// T returns the message for key with {name} placeholders replaced by the
+// given name/value pairs.
+func (l *Localizer) T(key string, args ...any) string {
+	return l.format(key, "other", args)
+}
+
+// N returns the plural form of key that matches count, with {count} and any
+// extra name/value pairs substituted.
+func (l *Localizer) N(key string, count int, args ...any) string {
+	rule, ok := pluralRules[l.Lang]
+	if !ok {
+		rule = pluralRules[defaultLocale]
+	}
+	args = append([]any{"count", count}, args...)
+	return l.format(key, rule(count), args)
+}
+
+func (l *Localizer) format(key, form string, args []any) string {
+	text, ok := l.lookup(key, form)
+	if !ok {
+		return key
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		text = strings.ReplaceAll(text, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return text
+}
+
+func (l *Localizer) lookup(key, form string) (string, bool) {
+	if m, ok := l.messages[key]; ok {
+		if text, ok := m[form]; ok {
+			return text, true
+		}
+		return m["other"], true
+	}
+	if l.fallback != nil {
+		return l.fallback.lookup(key, form)
+	}
+	return "", false
+}
+
+// Locale describes an available catalog for the language picker.
+type Locale struct {
+	Tag  string
+	Name string
+}
+
+// availableLocales lists the loaded catalogs, each named in its own language.
+func availableLocales() []Locale {
+	locales := make([]Locale, 0, len(catalogs))
+	for tag, l := range catalogs {
+		locales = append(locales, Locale{Tag: tag, Name: l.T("locale.name")})
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i].Tag < locales[j].Tag })
+	return locales
+}
+
+// loadCatalogs reads locales/*.json from base and then from override, so an
+// override file can replace a built-in locale or add a new one.
+func loadCatalogs(base, override fs.FS) error {
+	loaded := map[string]map[string]message{}
+	for _, fsys := range []fs.FS{base, override} {
+		if fsys == nil {
+			continue
+		}
+		matches, err := fs.Glob(fsys, "locales/*.json")
+		if err != nil {
+			return err
+		}
+		for _, name := range matches {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			var messages map[string]message
+			if err := json.Unmarshal(data, &messages); err != nil {
+				return fmt.Errorf("parse %s: %w", name, err)
+			}
+			tag := strings.ToLower(strings.TrimSuffix(path.Base(name), ".json"))
+			loaded[tag] = messages
+		}
+	}
+
+	if _, ok := loaded[defaultLocale]; !ok {
+		return fmt.Errorf("no catalog for default locale %q", defaultLocale)
+	}
+	fallback := &Localizer{Lang: defaultLocale, messages: loaded[defaultLocale]}
+	catalogs = map[string]*Localizer{defaultLocale: fallback}
+	for tag, messages := range loaded {
+		if tag != defaultLocale {
+			catalogs[tag] = &Localizer{Lang: tag, messages: messages, fallback: fallback}
+		}
+	}
+	return nil
+}
+
+// matchLocale resolves a language tag such as "ja-JP" to a loaded catalog.
+func matchLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if alias, ok := localeAliases[tag]; ok {
+		tag = alias
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag, true
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if _, ok := catalogs[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// negotiateLocale picks the request locale from ?lang=, then the lang cookie,
+// then Accept-Language.
+func negotiateLocale(r *http.Request) string {
+	if lang, ok := matchLocale(r.URL.Query().Get("lang")); ok {
+		return lang
+	}
+	if c, err := r.Cookie("lang"); err == nil {
+		if lang, ok := matchLocale(c.Value); ok {
+			return lang
+		}
+	}
+	for _, tag := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if lang, ok := matchLocale(tag); ok {
+			return lang
+		}
+	}
+	return defaultLocale
+}
+
+// parseAcceptLanguage returns the tags of an Accept-Language header ordered
+// by their q values.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func localizerFor(r *http.Request) *Localizer {
+	return catalogs[negotiateLocale(r)]
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IconFinding is the contrast of one fill or stroke color of an SVG icon
+// against one background. Icons are graphics, so they are judged by the
+// non-text threshold of SC 1.4.11.
+type IconFinding struct {
+	File string `json:"file"`
+	// Line and Element locate the first shape painted with the color.
+	Line     int    `json:"line"`
+	Element  string `json:"element"`
+	Property string `json:"property"`
+	// Value is the color as written in the file, for example currentColor.
+	Value            string  `json:"value"`
+	ColorHex         string  `json:"colorHex"`
+	BackgroundName   string  `json:"backgroundName"`
+	BackgroundHex    string  `json:"backgroundHex"`
+	ContrastRatio    float64 `json:"contrastRatio"`
+	ContrastRatioRaw float64 `json:"contrastRatioRaw"`
+	Level            Level   `json:"level"`
+	Pass             bool    `json:"pass"`
+}
+
+// IconBackground is a background icons are checked against.
+type IconBackground struct {
+	Name string
+	Hex  string
+}
+
+// svgShapes are the elements that paint fill and stroke.
+var svgShapes = map[string]bool{
+	"path": true, "rect": true, "circle": true, "ellipse": true, "line": true,
+	"polyline": true, "polygon": true, "text": true, "tspan": true,
+}
+
+// svgNotRendered hold content that is only drawn when referenced, or not at
+// all.
+var svgNotRendered = map[string]bool{
+	"defs": true, "clipPath": true, "mask": true, "symbol": true, "pattern": true,
+	"marker": true, "linearGradient": true, "radialGradient": true, "filter": true,
+	"title": true, "desc": true, "metadata": true, "style": true,
+}
+
+// svgPaint is the inherited painting state of an SVG element.
+type svgPaint struct {
+	Fill, Stroke, Color        string
+	FillOpacity, StrokeOpacity float64
+	// GroupOpacity is the product of the opacity of the element and its
+	// ancestors.
+	GroupOpacity float64
+	StrokeWidth  string
+}
+
+// svgUse is one color painted by a shape.
+type svgUse struct {
+	Line     int
+	Element  string
+	Property string
+	Value    string
+	Color    cssColor
+}
+
+// svgPresentation are the presentation attributes that affect painting.
+var svgPresentation = []string{"fill", "stroke", "color", "stroke-width", "fill-opacity", "stroke-opacity", "opacity"}
+
+// svgCascade returns the winning declaration of every property set on e.
+// Presentation attributes come first in the cascade, then the document's
+// style rules, then the style attribute, as in browsers.
+func svgCascade(e *htmlElement, rules []cssRule) map[string]cssDecl {
+	var decls []cascadeDecl
+	for _, name := range svgPresentation {
+		if v, ok := e.Attrs[name]; ok {
+			decls = append(decls, cascadeDecl{cssDecl: cssDecl{Prop: name, Value: v}, rank: rankDefault})
+		}
+	}
+	decls = append(decls, matchedDecls(e, rules, rankAuthor)...)
+	decls = append(decls, inlineDecls(e, "")...)
+	return winningDecls(decls)
+}
+
+// parseSVGTree reads an SVG document into a tree of elements, keeping the
+// text of its style elements.
+func parseSVGTree(r io.Reader) (*htmlElement, []htmlStyleSource, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	doc := &htmlElement{}
+	stack := []*htmlElement{doc}
+	var styles []htmlStyleSource
+	for {
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &htmlElement{Tag: t.Name.Local, Attrs: map[string]string{}, Line: line}
+			for _, a := range t.Attr {
+				e.Attrs[a.Name.Local] = a.Value
+			}
+			top.appendChild(e)
+			stack = append(stack, e)
+		case xml.CharData:
+			if top.Tag == "style" {
+				styles = append(styles, htmlStyleSource{Text: string(t), Line: line})
+			}
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if len(doc.Children) == 0 {
+		return nil, nil, errors.New("no root element")
+	}
+	root := doc.Children[0]
+	root.Parent = nil
+	return root, styles, nil
+}
+
+// svgPaintColors reads the fill and stroke colors of every rendered shape in
+// an SVG document, applying its style elements. currentColor is what the
+// currentColor keyword stands for; when it is empty, the color attribute or
+// property in effect is used. Paints that cannot be checked, such as
+// gradients, are returned as warnings.
+func svgPaintColors(r io.Reader, currentColor string) ([]svgUse, []string, error) {
+	root, styles, err := parseSVGTree(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rules []cssRule
+	for _, src := range styles {
+		for _, rule := range parseCSS(src.Text, "", src.Line) {
+			rule.Order = len(rules)
+			rules = append(rules, rule)
+		}
+	}
+
+	var uses []svgUse
+	var warnings []string
+	// paintShape records the fill and stroke of a shape painted with p.
+	paintShape := func(e *htmlElement, p svgPaint) {
+		line := e.Line
+		element := e.Tag
+		if id := e.Attrs["id"]; id != "" {
+			element += "#" + id
+		}
+		paints := []struct {
+			property, value string
+			opacity         float64
+		}{{"fill", p.Fill, p.FillOpacity * p.GroupOpacity}}
+		if w, err := strconv.ParseFloat(strings.TrimSuffix(p.StrokeWidth, "px"), 64); err != nil || w > 0 {
+			paints = append(paints, struct {
+				property, value string
+				opacity         float64
+			}{"stroke", p.Stroke, p.StrokeOpacity * p.GroupOpacity})
+		}
+		for _, paint := range paints {
+			value := paint.value
+			if strings.EqualFold(value, "none") || paint.opacity == 0 {
+				continue
+			}
+			resolved := value
+			if strings.EqualFold(value, "currentColor") {
+				if p.Color == "" {
+					warnings = append(warnings, fmt.Sprintf("line %d: %s %s is currentColor but no color is set", line, element, paint.property))
+					continue
+				}
+				resolved = p.Color
+			}
+			c, ok := parseCSSColor(resolved)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("line %d: %s %s %q was not checked", line, element, paint.property, value))
+				continue
+			}
+			c.A *= paint.opacity
+			if c.A == 0 {
+				continue
+			}
+			uses = append(uses, svgUse{Line: line, Element: element, Property: paint.property, Value: value, Color: c})
+		}
+	}
+	var walk func(e *htmlElement, p svgPaint)
+	walk = func(e *htmlElement, p svgPaint) {
+		if svgNotRendered[e.Tag] {
+			return
+		}
+		decls := svgCascade(e, rules)
+		property := func(name string) (string, bool) {
+			d, ok := decls[name]
+			return strings.TrimSpace(d.Value), ok
+		}
+		opacity := 1.0
+		for _, name := range []string{"fill", "stroke", "color", "stroke-width"} {
+			v, ok := property(name)
+			if !ok || v == "inherit" {
+				continue
+			}
+			switch name {
+			case "fill":
+				p.Fill = v
+			case "stroke":
+				p.Stroke = v
+			case "color":
+				if currentColor == "" {
+					p.Color = v
+				}
+			case "stroke-width":
+				p.StrokeWidth = v
+			}
+		}
+		for _, o := range []struct {
+			name string
+			dst  *float64
+		}{{"fill-opacity", &p.FillOpacity}, {"stroke-opacity", &p.StrokeOpacity}, {"opacity", &opacity}} {
+			if v, ok := property(o.name); ok {
+				if n, ok := cssNumber(v, 1); ok {
+					*o.dst = max(0, min(1, n))
+				}
+			}
+		}
+		p.GroupOpacity *= opacity
+		if svgShapes[e.Tag] {
+			paintShape(e, p)
+		}
+		for _, c := range e.Children {
+			walk(c, p)
+		}
+	}
+	walk(root, svgPaint{Fill: "black", Stroke: "none", Color: currentColor, FillOpacity: 1, StrokeOpacity: 1, GroupOpacity: 1, StrokeWidth: "1"})
+	return uses, warnings, nil
+}
+
+// checkIcon reports each distinct fill and stroke color of an SVG icon
+// against each background. Translucent colors are blended over the
+// background first.
+func checkIcon(file string, r io.Reader, currentColor string, backgrounds []IconBackground) ([]IconFinding, []string, error) {
+	uses, warnings, err := svgPaintColors(r, currentColor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for i, w := range warnings {
+		warnings[i] = file + ": " + w
+	}
+
+	seen := map[string]bool{}
+	var findings []IconFinding
+	for _, bg := range backgrounds {
+		bgColor, ok := parseCSSColor(bg.Hex)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid background %s", bg.Hex)
+		}
+		for _, u := range uses {
+			hex := u.Color.over(bgColor).hex()
+			key := strings.Join([]string{bg.Name, u.Property, u.Value, hex}, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ratio, err := contrastRatio(hex, bgColor.hex())
+			if err != nil {
+				return nil, nil, err
+			}
+			level := levelFor(TargetNonText, ratio)
+			findings = append(findings, IconFinding{
+				File:             file,
+				Line:             u.Line,
+				Element:          u.Element,
+				Property:         u.Property,
+				Value:            u.Value,
+				ColorHex:         hex,
+				BackgroundName:   bg.Name,
+				BackgroundHex:    bgColor.hex(),
+				ContrastRatio:    DisplayRatio(ratio),
+				ContrastRatioRaw: ratio,
+				Level:            level,
+				Pass:             level >= LevelAA,
+			})
+		}
+	}
+	return findings, warnings, nil
+}
+
+// iconBackgrounds resolves background references as in usage rules: dark
+// palette names or #rrggbb values. No references means every dark palette
+// color.
+func iconBackgrounds(colors *ColorSets, refs []string) ([]IconBackground, error) {
+	if len(refs) == 0 {
+		for name := range colors.Dark {
+			refs = append(refs, name)
+		}
+		sort.Strings(refs)
+	}
+	var bgs []IconBackground
+	for _, ref := range refs {
+		name, hex, ok := resolveUsageColor(colors.Dark, ref)
+		if !ok {
+			return nil, fmt.Errorf("unknown background %q", ref)
+		}
+		if _, err := hexRGBA(hex); err != nil {
+			return nil, fmt.Errorf("background %q: %w", ref, err)
+		}
+		bgs = append(bgs, IconBackground{Name: name, Hex: hex})
+	}
+	return bgs, nil
+}
+
+// iconCurrentColor resolves the token currentColor stands for, a light
+// palette name or #rrggbb value, as foregrounds are in usage rules.
+func iconCurrentColor(colors *ColorSets, ref string) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	_, hex, ok := resolveUsageColor(colors.Light, ref)
+	if !ok {
+		return "", fmt.Errorf("unknown current color %q", ref)
+	}
+	return hex, nil
+}
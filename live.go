@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// paletteChange names a palette whose colors changed: a stored palette, or
+// colors.json when both fields are empty.
+type paletteChange struct {
+	Project, Palette string
+}
+
+// paletteChanges tells the open event streams about palette changes.
+var paletteChanges = &broadcaster{subs: map[chan paletteChange]bool{}}
+
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan paletteChange]bool
+}
+
+// subscribe returns a channel of changes and a function that closes it.
+// Changes are dropped while the subscriber is busy, as it reloads the
+// palette anyway.
+func (b *broadcaster) subscribe() (<-chan paletteChange, func()) {
+	ch := make(chan paletteChange, 8)
+	b.mu.Lock()
+	b.subs[ch] = true
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *broadcaster) publish(c paletteChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- c:
+		default:
+		}
+	}
+}
+
+// watchColorsFile publishes a change whenever the content of filename
+// changes, checking every interval. Files that do not parse are skipped
+// until they are written completely.
+func watchColorsFile(ctx context.Context, filename string, interval time.Duration) {
+	last, _ := os.ReadFile(filename)
+	var modTime time.Time
+	if fi, err := os.Stat(filename); err == nil {
+		modTime = fi.ModTime()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fi, err := os.Stat(filename)
+		if err != nil || fi.ModTime().Equal(modTime) {
+			continue
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil || bytes.Equal(data, last) {
+			continue
+		}
+		var colors ColorSets
+		if err := json.Unmarshal(data, &colors); err != nil {
+			slog.Warn("colors.json changed but does not parse", "error", err)
+			continue
+		}
+		modTime, last = fi.ModTime(), data
+		slog.Info("colors.json changed")
+		paletteChanges.publish(paletteChange{})
+	}
+}
+
+// PaletteReload is the data of a palette-reloaded event.
+type PaletteReload struct {
+	Project string `json:"project"`
+	Palette string `json:"palette"`
+	Version int    `json:"version"`
+}
+
+// ResultsChange is the data of a results-changed event: how the pairs of
+// the whole palette differ from the last event, or from when the stream
+// was opened.
+type ResultsChange struct {
+	PaletteReload
+	Totals LevelCounts `json:"totals"`
+	// Changed are the new pairs and the pairs whose ratio changed.
+	// PreviousCategory is null for new pairs.
+	Changed []PairChange `json:"changed"`
+	// Removed are the pairs of colors that were removed or became invalid.
+	Removed []ContrastResult `json:"removed"`
+}
+
+// diffResults compares the pairs of two versions of a palette.
+func diffResults(before, after []ContrastResult) (changed []PairChange, removed []ContrastResult) {
+	type pair struct{ fg, bg string }
+	old := map[pair]ContrastResult{}
+	for _, r := range before {
+		old[pair{r.ForegroundName, r.BackgroundName}] = r
+	}
+	changed, removed = []PairChange{}, []ContrastResult{}
+	for _, r := range after {
+		key := pair{r.ForegroundName, r.BackgroundName}
+		prev, ok := old[key]
+		delete(old, key)
+		switch {
+		case !ok:
+			changed = append(changed, PairChange{ContrastResult: r})
+		case prev.ContrastRatioRaw != r.ContrastRatioRaw:
+			changed = append(changed, PairChange{ContrastResult: r, PreviousCategory: &prev.Category})
+		}
+	}
+	for _, r := range before {
+		if _, ok := old[pair{r.ForegroundName, r.BackgroundName}]; ok {
+			removed = append(removed, r)
+		}
+	}
+	return changed, removed
+}
+
+// eventsHandler streams Server-Sent Events about the palette of the
+// request: palette-reloaded whenever it is saved or colors.json is
+// written, followed by results-changed when that changed any pair.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	palette, err := requestPalette(r)
+	if err != nil {
+		httpError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+	changes, unsubscribe := paletteChanges.subscribe()
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		slog.ErrorContext(r.Context(), "Event stream cannot be flushed", "error", err)
+		return
+	}
+
+	send := func(event string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	results, _ := contrastResults(r.Context(), &palette.ColorSets)
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+			continue
+		case c := <-changes:
+			if c != (paletteChange{palette.Project, palette.Name}) {
+				continue
+			}
+		}
+
+		next, err := requestPalette(r)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Changed palette could not be reloaded", "error", err)
+			continue
+		}
+		if next.Version != 0 && next.Version == palette.Version {
+			continue
+		}
+		palette = next
+		reload := PaletteReload{Project: palette.Project, Palette: palette.Name, Version: palette.Version}
+		if err := send("palette-reloaded", reload); err != nil {
+			return
+		}
+
+		nextResults, _ := contrastResults(r.Context(), &palette.ColorSets)
+		changed, removed := diffResults(results, nextResults)
+		results = nextResults
+		if len(changed) == 0 && len(removed) == 0 {
+			continue
+		}
+		levels := bucketResults(results)
+		err = send("results-changed", ResultsChange{
+			PaletteReload: reload,
+			Totals: LevelCounts{
+				AAA:     len(levels.AAA),
+				AA:      len(levels.AA),
+				AALarge: len(levels.AALarge),
+				Fail:    len(levels.Fail),
+			},
+			Changed: changed,
+			Removed: removed,
+		})
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"sort"
+)
+
+// MatrixColor is one row or column heading of the matrix.
+type MatrixColor struct {
+	Name string
+	Hex  string
+}
+
+// MatrixRow is one foreground color and its result against every column.
+// A cell is nil when the pair was filtered out.
+type MatrixRow struct {
+	Foreground MatrixColor
+	Cells      []*ContrastResult
+}
+
+// Matrix lays results out as a grid of foregrounds (rows) by backgrounds
+// (columns), both in name order.
+type Matrix struct {
+	Backgrounds []MatrixColor
+	Rows        []MatrixRow
+}
+
+func buildMatrix(results []ContrastResult) Matrix {
+	fgs := map[string]MatrixColor{}
+	bgs := map[string]MatrixColor{}
+	for _, r := range results {
+		fgs[r.ForegroundName] = MatrixColor{r.ForegroundName, r.ForegroundHex}
+		bgs[r.BackgroundName] = MatrixColor{r.BackgroundName, r.BackgroundHex}
+	}
+	sorted := func(m map[string]MatrixColor) []MatrixColor {
+		out := make([]MatrixColor, 0, len(m))
+		for _, c := range m {
+			out = append(out, c)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+		return out
+	}
+
+	m := Matrix{Backgrounds: sorted(bgs)}
+	column := map[string]int{}
+	for j, bg := range m.Backgrounds {
+		column[bg.Name] = j
+	}
+	row := map[string]int{}
+	for i, fg := range sorted(fgs) {
+		row[fg.Name] = i
+		m.Rows = append(m.Rows, MatrixRow{Foreground: fg, Cells: make([]*ContrastResult, len(m.Backgrounds))})
+	}
+	for i := range results {
+		r := &results[i]
+		m.Rows[row[r.ForegroundName]].Cells[column[r.BackgroundName]] = r
+	}
+	return m
+}
+
+// Geometry of the exported matrix images, in pixels.
+const (
+	matrixPad     = 16
+	matrixCellW   = 104
+	matrixCellH   = 64
+	matrixHeaderH = 44
+	matrixSwatch  = 14
+	matrixLegendH = 36
+	matrixEmpty   = "#eeeeee"
+	matrixInk     = "#222222"
+	matrixMuted   = "#666666"
+	matrixOutline = "#999999"
+	matrixPaper   = "#ffffff"
+)
+
+var (
+	matrixLabel  = textStyle{Font: "regular", Size: 13}
+	matrixHex    = textStyle{Font: "regular", Size: 11}
+	matrixSample = textStyle{Font: "bold", Size: 18}
+	matrixBadge  = textStyle{Font: "regular", Size: 10, End: true}
+)
+
+// legend lists the categories in the order they are drawn at the bottom.
+var legend = []Category{CategoryAAA, CategoryAA, CategoryAALarge, CategoryFail}
+
+// size returns the size of the rendered matrix and the width of its row
+// labels.
+func (m Matrix) size() (width, height, labelW int) {
+	labelW = 80
+	for _, row := range m.Rows {
+		if lw := matrixSwatch + 8 + textWidth(matrixLabel.Font, matrixLabel.Size, row.Foreground.Name) + 12; lw > labelW {
+			labelW = lw
+		}
+	}
+	width = 2*matrixPad + labelW + len(m.Backgrounds)*matrixCellW
+	height = 2*matrixPad + matrixHeaderH + len(m.Rows)*matrixCellH + matrixLegendH
+	return width, height, labelW
+}
+
+// render draws the matrix as an "svg" or "png" image.
+func (m Matrix) render(w io.Writer, format string) error {
+	width, height, labelW := m.size()
+
+	return renderImage(w, format, width, height, func(c canvas) {
+		c.rect(image.Rect(0, 0, width, height), matrixPaper)
+		swatch := func(x, y int, hex string) {
+			c.rect(image.Rect(x, y, x+matrixSwatch, y+matrixSwatch), matrixOutline)
+			c.rect(image.Rect(x+1, y+1, x+matrixSwatch-1, y+matrixSwatch-1), hex)
+		}
+
+		for j, bg := range m.Backgrounds {
+			x := matrixPad + labelW + j*matrixCellW
+			swatch(x, matrixPad, bg.Hex)
+			c.text(x+matrixSwatch+6, matrixPad+12, matrixLabel, matrixInk, bg.Name)
+			c.text(x, matrixPad+32, matrixHex, matrixMuted, bg.Hex)
+		}
+
+		for i, row := range m.Rows {
+			y := matrixPad + matrixHeaderH + i*matrixCellH
+			swatch(matrixPad, y+matrixCellH/2-matrixSwatch/2-1, row.Foreground.Hex)
+			c.text(matrixPad+matrixSwatch+8, y+matrixCellH/2+4, matrixLabel, matrixInk, row.Foreground.Name)
+
+			for j, r := range row.Cells {
+				x := matrixPad + labelW + j*matrixCellW
+				cell := image.Rect(x, y, x+matrixCellW-2, y+matrixCellH-2)
+				if r == nil {
+					c.rect(cell, matrixEmpty)
+					continue
+				}
+				title := fmt.Sprintf("%s on %s: %.2f (%s)", r.ForegroundName, r.BackgroundName, r.ContrastRatio, r.Category)
+				c.clip(cell, title, func() {
+					c.rect(cell, r.BackgroundHex)
+					c.text(cell.Min.X+8, cell.Min.Y+24, matrixSample, r.ForegroundHex, "Aa")
+					c.text(cell.Min.X+8, cell.Min.Y+46, matrixLabel, r.ForegroundHex, fmt.Sprintf("%.2f", r.ContrastRatio))
+					c.text(cell.Max.X-6, cell.Min.Y+14, matrixBadge, r.ForegroundHex, r.Category.String())
+					c.rect(image.Rect(cell.Min.X, cell.Max.Y-6, cell.Max.X, cell.Max.Y), categoryColors[r.Category])
+				})
+			}
+		}
+
+		y := height - matrixPad - matrixLegendH + 20
+		x := matrixPad
+		for _, category := range legend {
+			c.rect(image.Rect(x, y-11, x+matrixSwatch, y-11+matrixSwatch), categoryColors[category])
+			c.text(x+matrixSwatch+6, y, matrixLabel, matrixInk, category.String())
+			x += 110
+		}
+	})
+}
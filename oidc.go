@@ -0,0 +1,398 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDCConfig verifies bearer ID tokens issued by an OpenID Connect
+// provider. The issuer may be any server with a discovery document,
+// including a local stand-in for development.
+type OIDCConfig struct {
+	Issuer string `json:"issuer"`
+	// Audience is the client ID tokens must be issued to.
+	Audience string `json:"audience"`
+	// UsernameClaim names the user in the history, "email" unless given,
+	// falling back to "sub".
+	UsernameClaim string `json:"usernameClaim"`
+	// RoleClaim holds the user's groups or roles, "roles" unless given.
+	RoleClaim string `json:"roleClaim"`
+	// Roles maps values of RoleClaim to roles; the highest one applies.
+	Roles map[string]Role `json:"roles"`
+	// DefaultRole is the role of users with none of the mapped values.
+	DefaultRole Role `json:"defaultRole"`
+}
+
+// jwksRefreshInterval limits how often an unknown key ID refetches the
+// provider's keys.
+const (
+	jwksRefreshInterval = time.Minute
+	clockSkew           = time.Minute
+)
+
+type oidcVerifier struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	// fetchMu serializes fetches and guards jwksURI; mu guards the rest.
+	fetchMu sync.Mutex
+	jwksURI string
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newOIDCVerifier(cfg OIDCConfig) (*oidcVerifier, error) {
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("issuer and audience are required")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "email"
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "roles"
+	}
+	// Keys are fetched on first use, so the server starts while the
+	// provider is down.
+	return &oidcVerifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (v *oidcVerifier) Challenge() string { return `Bearer realm="contrast-checker"` }
+
+// Authenticate takes bearer tokens that look like JWTs; anything else is
+// left to other authenticators.
+func (v *oidcVerifier) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if strings.Count(token, ".") != 2 {
+		return nil, nil
+	}
+	claims, err := v.verify(token)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Rejected OIDC token", "error", err)
+		return nil, errBadCredentials
+	}
+
+	name, _ := claims[v.cfg.UsernameClaim].(string)
+	if name == "" {
+		name, _ = claims["sub"].(string)
+	}
+	role := v.cfg.DefaultRole
+	var values []string
+	switch c := claims[v.cfg.RoleClaim].(type) {
+	case string:
+		values = []string{c}
+	case []any:
+		for _, value := range c {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	for _, value := range values {
+		if mapped, ok := v.cfg.Roles[value]; ok && mapped > role {
+			role = mapped
+		}
+	}
+	return &Principal{Name: name, Role: role, Method: "oidc"}, nil
+}
+
+// verify checks the signature, issuer, audience, and lifetime of a JWT and
+// returns its claims.
+func (v *oidcVerifier) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWS(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("claims: %w", err)
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != v.cfg.Issuer {
+		return nil, fmt.Errorf("issuer %q is not %q", iss, v.cfg.Issuer)
+	}
+	var aud []string
+	switch a := claims["aud"].(type) {
+	case string:
+		aud = []string{a}
+	case []any:
+		for _, value := range a {
+			if s, ok := value.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+	}
+	if !slices.Contains(aud, v.cfg.Audience) {
+		return nil, fmt.Errorf("audience %v does not include %q", aud, v.cfg.Audience)
+	}
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// minRSABits is the shortest RSA key accepted, as required by RFC 7518.
+const minRSABits = 2048
+
+// esCurves is the curve each ES* algorithm is defined for.
+var esCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifyJWS checks an RS*, PS*, or ES* signature. Other algorithms,
+// including "none" and the HMAC ones, are refused, as are ES* algorithms
+// used with another curve than their own and RSA keys under minRSABits.
+func verifyJWS(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	if len(alg) == 5 {
+		switch alg[2:] {
+		case "256":
+			hash = crypto.SHA256
+		case "384":
+			hash = crypto.SHA384
+		case "512":
+			hash = crypto.SHA512
+		}
+	}
+	if hash == 0 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return fmt.Errorf("RSA key of %d bits is too short", k.N.BitLen())
+		}
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, sig, nil)
+		}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if alg[:2] == "ES" && esCurves[alg] == k.Curve && len(sig) == 2*size {
+			r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(k, digest, r, s) {
+				return nil
+			}
+			return errors.New("invalid signature")
+		}
+	}
+	return fmt.Errorf("algorithm %q does not match the key", alg)
+}
+
+// key returns the provider's key with the given ID, fetching the keys
+// again when it is unknown. Requests wait on v.mu only to read the keys;
+// a fetch holds fetchMu, so one request at a time goes to the provider.
+func (v *oidcVerifier) key(kid string) (crypto.PublicKey, error) {
+	key, stale := v.cachedKey(kid)
+	if key == nil && stale {
+		var err error
+		if key, err = v.refreshKey(kid); err != nil {
+			return nil, err
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// refreshKey fetches the keys again, unless another request did while
+// this one waited, and looks kid up in them.
+func (v *oidcVerifier) refreshKey(kid string) (crypto.PublicKey, error) {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if key, stale := v.cachedKey(kid); key != nil || !stale {
+		return key, nil
+	}
+	keys, err := v.fetchKeys()
+	v.mu.Lock()
+	// A failed fetch also waits out the interval before the next one.
+	v.fetched = time.Now()
+	if err == nil {
+		v.keys = keys
+	}
+	v.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("fetch keys from %s: %w", v.cfg.Issuer, err)
+	}
+	key, _ := v.cachedKey(kid)
+	return key, nil
+}
+
+// cachedKey looks kid up in the keys fetched last and reports whether they
+// are old enough to fetch again.
+func (v *oidcVerifier) cachedKey(kid string) (key crypto.PublicKey, stale bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, false
+	}
+	// Providers with a single key may leave out key IDs.
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, false
+		}
+	}
+	return nil, time.Since(v.fetched) >= jwksRefreshInterval
+}
+
+func (v *oidcVerifier) getJSON(url string, dst any) error {
+	resp, err := v.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// jwk is a JSON Web Key of type RSA or EC.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *oidcVerifier) fetchKeys() (map[string]crypto.PublicKey, error) {
+	if v.jwksURI == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(v.cfg.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, err
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != v.cfg.Issuer {
+			return nil, fmt.Errorf("discovery names issuer %q", discovery.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("discovery has no jwks_uri")
+		}
+		v.jwksURI = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(v.jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.Warn("Skipped OIDC key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	b64 := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid key parameter %q", s)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := b64(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testAudience = "contrast-checker"
+
+// testProvider is an OIDC provider that serves discovery and a key set
+// that tests can change.
+type testProvider struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]crypto.Signer
+	fetches int
+}
+
+func newTestProvider(t *testing.T, keys map[string]crypto.Signer) *testProvider {
+	t.Helper()
+	p := &testProvider{keys: keys}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": p.URL, "jwks_uri": p.URL + "/keys"})
+		case "/keys":
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.fetches++
+			var set struct {
+				Keys []map[string]string `json:"keys"`
+			}
+			for kid, key := range p.keys {
+				set.Keys = append(set.Keys, testJWK(kid, key.Public()))
+			}
+			json.NewEncoder(w).Encode(set)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) setKeys(keys map[string]crypto.Signer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+}
+
+func (p *testProvider) fetchCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetches
+}
+
+func testJWK(kid string, key crypto.PublicKey) map[string]string {
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwk := map[string]string{"use": "sig"}
+	if kid != "" {
+		jwk["kid"] = kid
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = b64(k.N.Bytes())
+		jwk["e"] = b64(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk["kty"] = "EC"
+		jwk["crv"] = k.Curve.Params().Name
+		jwk["x"] = b64(k.X.FillBytes(make([]byte, size)))
+		jwk["y"] = b64(k.Y.FillBytes(make([]byte, size)))
+	}
+	return jwk
+}
+
+// signTestToken makes a JWT with the given header and claims. Other tokens
+// are signed with key, RSA ones with PKCS #1 v1.5 even for PS*, whatever
+// its size or curve; HS* tokens are signed with secret and "none" tokens
+// not at all.
+func signTestToken(t *testing.T, alg, kid string, claims map[string]any, key crypto.Signer, secret []byte) string {
+	t.Helper()
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	part := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := part(header) + "." + part(claims)
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[len(alg)-3:]]
+	var sig []byte
+	switch {
+	case alg == "none":
+	case strings.HasPrefix(alg, "HS"):
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	default:
+		h := hash.New()
+		h.Write([]byte(signed))
+		digest := h.Sum(nil)
+		var err error
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		case *ecdsa.PrivateKey:
+			var r, s *big.Int
+			r, s, err = ecdsa.Sign(rand.Reader, k, digest)
+			size := (k.Curve.Params().BitSize + 7) / 8
+			sig = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func testRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestOIDCVerify(t *testing.T) {
+	rsaKey := testRSAKey(t, 2048)
+	shortKey := testRSAKey(t, 1024)
+	p256 := testECKey(t, elliptic.P256())
+	p384 := testECKey(t, elliptic.P384())
+	provider := newTestProvider(t, map[string]crypto.Signer{"rsa": rsaKey, "short": shortKey, "p256": p256, "p384": p384})
+	v, err := newOIDCVerifier(OIDCConfig{Issuer: provider.URL, Audience: testAudience})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	claims := func(change func(map[string]any)) map[string]any {
+		c := map[string]any{"iss": provider.URL, "aud": testAudience, "sub": "ana", "exp": now.Add(time.Hour).Unix()}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	publicJWK, _ := json.Marshal(testJWK("rsa", rsaKey.Public()))
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"RS256", signTestToken(t, "RS256", "rsa", claims(nil), rsaKey, nil), ""},
+		{"ES256", signTestToken(t, "ES256", "p256", claims(nil), p256, nil), ""},
+		{"ES384", signTestToken(t, "ES384", "p384", claims(nil), p384, nil), ""},
+		{"audience list", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["aud"] = []string{"other", testAudience} }), rsaKey, nil), ""},
+		{"issuer with trailing slash", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["iss"] = provider.URL + "/" }), rsaKey, nil), ""},
+		{"alg of another key type", signTestToken(t, "ES256", "rsa", claims(nil), p256, nil), "does not match the key"},
+		{"PS256 over an RS256 signature", signTestToken(t, "PS256", "rsa", claims(nil), rsaKey, nil), "verification error"},
+		{"none", signTestToken(t, "none", "rsa", claims(nil), nil, nil), "unsupported algorithm"},
+		{"HS256 with the public key", signTestToken(t, "HS256", "rsa", claims(nil), nil, publicJWK), "does not match the key"},
+		{"ES512 with a P-256 key", signTestToken(t, "ES512", "p256", claims(nil), p256, nil), "does not match the key"},
+		{"ES256 with a P-384 key", signTestToken(t, "ES256", "p384", claims(nil), p384, nil), "does not match the key"},
+		{"RSA key under 2048 bits", signTestToken(t, "RS256", "short", claims(nil), shortKey, nil), "too short"},
+		{"signed by another key", signTestToken(t, "RS256", "rsa", claims(nil), testRSAKey(t, 2048), nil), "verification error"},
+		{"wrong audience", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["aud"] = "other" }), rsaKey, nil), "audience"},
+		{"no audience", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { delete(c, "aud") }), rsaKey, nil), "audience"},
+		{"wrong issuer", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["iss"] = "https://evil.example" }), rsaKey, nil), "issuer"},
+		{"expired", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["exp"] = now.Add(-clockSkew - time.Minute).Unix() }), rsaKey, nil), "expired"},
+		{"expired within the clock skew", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["exp"] = now.Add(-clockSkew / 2).Unix() }), rsaKey, nil), ""},
+		{"no expiry", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { delete(c, "exp") }), rsaKey, nil), "expired"},
+		{"not valid yet", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["nbf"] = now.Add(clockSkew + time.Minute).Unix() }), rsaKey, nil), "not valid yet"},
+		{"valid from now", signTestToken(t, "RS256", "rsa", claims(func(c map[string]any) { c["nbf"] = now.Unix() }), rsaKey, nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.verify(tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verify: %v", err)
+				}
+				if got["sub"] != "ana" {
+					t.Errorf("sub = %v, want ana", got["sub"])
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("verify error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	oldKey, newKey := testRSAKey(t, 2048), testRSAKey(t, 2048)
+	provider := newTestProvider(t, map[string]crypto.Signer{"old": oldKey})
+	v, err := newOIDCVerifier(OIDCConfig{Issuer: provider.URL, Audience: testAudience})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]any{"iss": provider.URL, "aud": testAudience, "sub": "ana", "exp": time.Now().Add(time.Hour).Unix()}
+
+	if _, err := v.verify(signTestToken(t, "RS256", "old", claims, oldKey, nil)); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+	provider.setKeys(map[string]crypto.Signer{"old": oldKey, "new": newKey})
+	newToken := signTestToken(t, "RS256", "new", claims, newKey, nil)
+
+	// Unknown keys are only fetched once per jwksRefreshInterval.
+	if _, err := v.verify(newToken); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Fatalf("new key within the refresh interval: error = %v, want unknown key", err)
+	}
+	if n := provider.fetchCount(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+
+	v.mu.Lock()
+	v.fetched = v.fetched.Add(-jwksRefreshInterval)
+	v.mu.Unlock()
+	if _, err := v.verify(newToken); err != nil {
+		t.Fatalf("new key after the refresh interval: %v", err)
+	}
+	if n := provider.fetchCount(); n != 2 {
+		t.Fatalf("fetches = %d, want 2", n)
+	}
+	if _, err := v.verify(newToken); err != nil {
+		t.Fatalf("new key again: %v", err)
+	}
+	if n := provider.fetchCount(); n != 2 {
+		t.Fatalf("fetches = %d after a known key, want 2", n)
+	}
+}
+
+func TestOIDCSingleKeyFallback(t *testing.T) {
+	key, other := testRSAKey(t, 2048), testRSAKey(t, 2048)
+	claims := map[string]any{"sub": "ana", "aud": testAudience, "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		keys    map[string]crypto.Signer
+		wantErr string
+	}{
+		{"one key", map[string]crypto.Signer{"only": key}, ""},
+		{"one key without an ID", map[string]crypto.Signer{"": key}, ""},
+		{"several keys", map[string]crypto.Signer{"a": key, "b": other}, "unknown key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(t, tt.keys)
+			v, err := newOIDCVerifier(OIDCConfig{Issuer: provider.URL, Audience: testAudience})
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims["iss"] = provider.URL
+			token := signTestToken(t, "RS256", "", claims, key, nil)
+			// The second call must use the cached key without a refetch.
+			for i := 0; i < 2; i++ {
+				_, err = v.verify(token)
+				if tt.wantErr == "" && err != nil {
+					t.Fatalf("verify %d: %v", i, err)
+				}
+				if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+					t.Fatalf("verify %d error = %v, want one containing %q", i, err, tt.wantErr)
+				}
+			}
+			if n := provider.fetchCount(); n != 1 {
+				t.Errorf("fetches = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestOIDCConcurrentFetch(t *testing.T) {
+	key := testRSAKey(t, 2048)
+	provider := newTestProvider(t, map[string]crypto.Signer{"k": key})
+	v, err := newOIDCVerifier(OIDCConfig{Issuer: provider.URL, Audience: testAudience})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signTestToken(t, "RS256", "k", map[string]any{"iss": provider.URL, "aud": testAudience, "sub": "ana", "exp": time.Now().Add(time.Hour).Unix()}, key, nil)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = v.verify(token)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("verify %d: %v", i, err)
+		}
+	}
+	if n := provider.fetchCount(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+}
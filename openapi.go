@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiOperation documents one operation of the JSON API. The OpenAPI
+// document is built from these and from the Go types the handlers encode,
+// so the two cannot drift apart.
+type apiOperation struct {
+	Method, Path string
+	// ID is the operationId, which the Go client uses as the method name.
+	ID          string
+	Summary     string
+	Description string
+	Role        Role
+	Params      []apiParam
+	// Body is a zero value of the JSON request body, and BodyContent the
+	// media type of any other body.
+	Body        any
+	BodyContent string
+	// Status is the success status, and Response a zero value of its JSON
+	// body or nil for none. ResponseContent is the media type of any other
+	// body. Also lists other statuses with the same body.
+	Status          int
+	Response        any
+	ResponseContent string
+	Also            []int
+	// Versioned operations send the palette's version as the ETag.
+	Versioned bool
+	// RootOnly operations are not served under /projects/{project}.
+	RootOnly bool
+}
+
+// apiParam is a query or header parameter.
+type apiParam struct {
+	Name, In, Type, Description string
+	Enum                        []string
+	Required                    bool
+}
+
+func queryParam(name, typ, description string, enum ...string) apiParam {
+	return apiParam{Name: name, In: "query", Type: typ, Description: description, Enum: enum}
+}
+
+var (
+	paletteParams = []apiParam{
+		queryParam("palette", "string", "stored palette to use instead of the project's"),
+		queryParam("version", "integer", "version of the palette to use instead of the current one"),
+	}
+	algorithmParam = queryParam("algorithm", "string", "algorithm that judges usage rules, the project's or wcag unless given", AlgorithmWCAG, AlgorithmAPCA)
+	resultParams   = []apiParam{
+		queryParam("q", "string", "search expression, as described in the README"),
+		queryParam("filter", "string", "only results in this category", "AAA", "AA", "AALarge", "Fail"),
+		queryParam("sort", "string", "field to sort by, foreground unless given", sortFieldNames()...),
+		queryParam("order", "string", "sort order, asc unless given", "asc", "desc"),
+		queryParam("page", "integer", "page number, from 1"),
+		queryParam("limit", "integer", fmt.Sprintf("results per page, at most %d", maxPageLimit)),
+		algorithmParam,
+	}
+	changeParams = []apiParam{
+		queryParam("author", "string", "author recorded in the history when the request is not signed in"),
+		queryParam("message", "string", "change note recorded in the history"),
+	}
+	ifMatch = apiParam{Name: "If-Match", In: "header", Type: "string",
+		Description: `ETag of the version the change is based on, or "*" for any version`}
+	requiredIfMatch = apiParam{Name: ifMatch.Name, In: ifMatch.In, Type: ifMatch.Type, Description: ifMatch.Description, Required: true}
+)
+
+func sortFieldNames() []string {
+	var names []string
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func params(groups ...[]apiParam) []apiParam {
+	return slices.Concat(groups...)
+}
+
+// apiOperations lists every operation of the JSON API.
+var apiOperations = []apiOperation{
+	{Method: "GET", Path: "/api/contrasts", ID: "ListContrasts", Summary: "One page of contrast results", Role: RoleViewer,
+		Params: params(resultParams, paletteParams), Status: 200, Response: ResultPage{}},
+	{Method: "GET", Path: "/api/usages", ID: "ListUsages", Summary: "Verdicts on the palette's usage rules", Role: RoleViewer,
+		Params: params([]apiParam{algorithmParam}, paletteParams), Status: 200, Response: UsageReport{}},
+	{Method: "GET", Path: "/api/palettes", ID: "ListPalettes", Summary: "The project's stored palettes", Role: RoleViewer,
+		Status: 200, Response: []StoredPalette{}},
+	{Method: "POST", Path: "/api/palettes", ID: "GeneratePalette", Summary: "Generate a palette from a seed color", Role: RoleViewer,
+		Body: PaletteSpec{}, Status: 200, Response: GeneratedPalette{}},
+	{Method: "GET", Path: "/api/palettes/{palette}", ID: "GetPalette", Summary: "A stored palette", Role: RoleViewer,
+		Status: 200, Response: StoredPalette{}, Versioned: true},
+	{Method: "PUT", Path: "/api/palettes/{palette}", ID: "PutPalette", Summary: "Create a palette, or replace it with If-Match", Role: RoleEditor,
+		Params: params([]apiParam{ifMatch}, changeParams), Body: ColorSets{}, Status: 200, Also: []int{201}, Response: StoredPalette{}, Versioned: true},
+	{Method: "DELETE", Path: "/api/palettes/{palette}", ID: "DeletePalette", Summary: "Delete a palette", Role: RoleEditor,
+		Params: params([]apiParam{requiredIfMatch}, changeParams), Status: 204},
+	{Method: "GET", Path: "/api/palettes/{palette}/themes/{theme}", ID: "GetTheme", Summary: "The colors of one theme", Role: RoleViewer,
+		Status: 200, Response: map[string]string{}, Versioned: true},
+	{Method: "PUT", Path: "/api/palettes/{palette}/themes/{theme}", ID: "PutTheme", Summary: "Replace the colors of one theme", Role: RoleEditor,
+		Params: params([]apiParam{requiredIfMatch}, changeParams), Body: map[string]string{}, Status: 200, Response: map[string]string{}, Versioned: true},
+	{Method: "DELETE", Path: "/api/palettes/{palette}/themes/{theme}", ID: "ClearTheme", Summary: "Remove every color of one theme", Role: RoleEditor,
+		Params: params([]apiParam{requiredIfMatch}, changeParams), Status: 204, Versioned: true},
+	{Method: "GET", Path: "/api/palettes/{palette}/themes/{theme}/colors/{color}", ID: "GetColor", Summary: "One color", Role: RoleViewer,
+		Status: 200, Response: PaletteColor{}, Versioned: true},
+	{Method: "PUT", Path: "/api/palettes/{palette}/themes/{theme}/colors/{color}", ID: "PutColor", Summary: "Add or change one color", Role: RoleEditor,
+		Description: "The value may be any opaque CSS color and is stored as #rrggbb.",
+		Params:      params([]apiParam{requiredIfMatch}, changeParams), Body: ColorValue{}, Status: 200, Also: []int{201}, Response: PaletteColor{}, Versioned: true},
+	{Method: "DELETE", Path: "/api/palettes/{palette}/themes/{theme}/colors/{color}", ID: "DeleteColor", Summary: "Remove one color", Role: RoleEditor,
+		Params: params([]apiParam{requiredIfMatch}, changeParams), Status: 204, Versioned: true},
+	{Method: "GET", Path: "/api/palettes/{palette}/versions", ID: "ListVersions", Summary: "The history of a palette, newest first", Role: RoleViewer,
+		Status: 200, Response: []PaletteVersion{}},
+	{Method: "GET", Path: "/api/palettes/{palette}/versions/{version}", ID: "GetVersion", Summary: "One version of a palette with its colors", Role: RoleViewer,
+		Status: 200, Response: PaletteVersion{}},
+	{Method: "POST", Path: "/api/palettes/{palette}/versions/{version}/rollback", ID: "RollbackPalette", Summary: "Save the colors of an earlier version as the next version", Role: RoleEditor,
+		Description: "Like a PUT of the whole palette, it needs If-Match unless the palette has been deleted.",
+		Params:      params([]apiParam{ifMatch}, changeParams), Status: 200, Response: StoredPalette{}, Versioned: true},
+	{Method: "POST", Path: "/api/images", ID: "AnalyzeImage", Summary: "Estimate the text contrast of a screenshot or mockup", Role: RoleViewer,
+		BodyContent: "multipart/form-data", Status: 200, Response: ImageAnalysis{}},
+	{Method: "POST", Path: "/api/images/heatmap.png", ID: "RenderHeatmap", Summary: "The contrast heatmap of a screenshot or mockup", Role: RoleViewer,
+		BodyContent: "multipart/form-data", Status: 200, ResponseContent: "image/png"},
+	{Method: "POST", Path: "/api/icons", ID: "CheckIcon", Summary: "Check the colors of an SVG icon against backgrounds", Role: RoleViewer,
+		Params: params([]apiParam{
+			queryParam("bg", "array", "dark palette name or #rrggbb to check against, every dark color unless given"),
+			queryParam("currentColor", "string", "light palette name or #rrggbb that currentColor stands for"),
+		}, paletteParams), BodyContent: "image/svg+xml", Status: 200, Response: IconReport{}},
+	{Method: "GET", Path: "/api/surfaces", ID: "CheckSurfaces", Summary: "Check one foreground on several backgrounds", Role: RoleViewer,
+		Description: "Reports the lowest and highest ratio and the limiting surface, the one with the lowest ratio.",
+		Params: params([]apiParam{
+			{Name: "foreground", In: "query", Type: "string", Description: "light palette name or #rrggbb", Required: true},
+			queryParam("bg", "array", "dark palette name or #rrggbb to check against, every dark color unless given with group"),
+			queryParam("group", "string", "dark palette names to check against, with * and ? wildcards"),
+		}, paletteParams), Status: 200, Response: SurfaceReport{}},
+	{Method: "GET", Path: "/api/export", ID: "ExportProject", Summary: "The project with all of its palettes", Role: RoleViewer,
+		Status: 200, Response: ProjectExport{}},
+	{Method: "GET", Path: "/api/check", ID: "CheckProject", Summary: "Hold a palette to the project's thresholds", Role: RoleViewer,
+		Description: "Answers 200 when the check passes and 422 when it fails.",
+		Params:      params([]apiParam{algorithmParam}, paletteParams), Status: 200, Also: []int{422}, Response: ProjectCheck{}},
+	{Method: "GET", Path: "/api/pair", ID: "CheckPair", Summary: "Check one pair of colors as the color picker does", Role: RoleViewer,
+		Description: "Adds APCA, color vision deficiency previews, and the nearest colors that reach the levels the pair misses.",
+		Params: []apiParam{
+			{Name: "foreground", In: "query", Type: "string", Description: "any opaque CSS color", Required: true},
+			{Name: "background", In: "query", Type: "string", Description: "any opaque CSS color", Required: true},
+		}, Status: 200, Response: PairReport{}},
+	{Method: "GET", Path: "/api/webhooks", ID: "ListWebhooks", Summary: "The project's webhooks", Role: RoleAdmin,
+		Status: 200, Response: []Webhook{}},
+	{Method: "POST", Path: "/api/webhooks", ID: "CreateWebhook", Summary: "Add a webhook, answering with its secret", Role: RoleAdmin,
+		Body: WebhookSettings{}, Status: 201, Response: Webhook{}},
+	{Method: "GET", Path: "/api/webhooks/{webhook}", ID: "GetWebhook", Summary: "A webhook", Role: RoleAdmin,
+		Status: 200, Response: Webhook{}},
+	{Method: "PUT", Path: "/api/webhooks/{webhook}", ID: "UpdateWebhook", Summary: "Replace the settings of a webhook", Role: RoleAdmin,
+		Description: "The secret is kept unless a new one is given.",
+		Body:        WebhookSettings{}, Status: 200, Response: Webhook{}},
+	{Method: "DELETE", Path: "/api/webhooks/{webhook}", ID: "DeleteWebhook", Summary: "Remove a webhook", Role: RoleAdmin, Status: 204},
+	{Method: "GET", Path: "/api/webhooks/{webhook}/deliveries", ID: "ListDeliveries", Summary: "The newest deliveries of a webhook", Role: RoleAdmin,
+		Params: []apiParam{queryParam("limit", "integer", fmt.Sprintf("deliveries to return, 50 unless given and at most %d", deliveriesKept))},
+		Status: 200, Response: []WebhookDelivery{}},
+	{Method: "GET", Path: "/api/projects", ID: "ListProjects", Summary: "Every project", Role: RoleViewer,
+		Status: 200, Response: []Project{}, RootOnly: true},
+	{Method: "POST", Path: "/api/projects", ID: "CreateProject", Summary: "Create a project", Role: RoleAdmin,
+		Body: Project{}, Status: 201, Response: Project{}, RootOnly: true},
+	{Method: "GET", Path: "/api/projects/{project}", ID: "GetProject", Summary: "A project", Role: RoleViewer,
+		Status: 200, Response: Project{}, RootOnly: true},
+	{Method: "PUT", Path: "/api/projects/{project}", ID: "SaveProject", Summary: "Change a project, creating it if needed", Role: RoleAdmin,
+		Body: ProjectUpdate{}, Status: 200, Also: []int{201}, Response: Project{}, RootOnly: true},
+	{Method: "DELETE", Path: "/api/projects/{project}", ID: "DeleteProject", Summary: "Delete a project with its palettes and history", Role: RoleAdmin,
+		Status: 204, RootOnly: true},
+	{Method: "GET", Path: "/api/openapi.json", ID: "GetOpenAPI", Summary: "This document", Role: RoleViewer,
+		Status: 200, Response: json.RawMessage{}, RootOnly: true},
+}
+
+// apiEnums are the types encoded as one of a set of strings.
+var apiEnums = map[reflect.Type][]string{
+	reflect.TypeFor[Level]():    {"AAA", "AA", "Fail"},
+	reflect.TypeFor[Category](): {"AAA", "AA", "AALarge", "Fail"},
+	reflect.TypeFor[Target]():   {string(TargetSmallText), string(TargetLargeText), string(TargetNonText)},
+	reflect.TypeFor[Role]():     {"none", "viewer", "editor", "admin"},
+}
+
+// openAPISchemas collects the named schemas of an OpenAPI document.
+type openAPISchemas map[string]any
+
+// ref returns the schema of t, adding named types to the components.
+func (s openAPISchemas) ref(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[json.RawMessage]():
+		return map[string]any{}
+	}
+	if enum, ok := apiEnums[t]; ok {
+		if _, done := s[t.Name()]; !done {
+			s[t.Name()] = map[string]any{"type": "string", "enum": enum}
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.ref(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": s.ref(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.ref(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, done := s[t.Name()]; !done {
+			s[t.Name()] = nil // guards against recursion
+			s[t.Name()] = s.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// object is the schema of a struct as encoding/json writes it: embedded
+// structs are flattened, and fields without omitempty are always present.
+func (s openAPISchemas) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				add(f.Type)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			schema := s.ref(f.Type)
+			omitempty := strings.Contains(opts, "omitempty")
+			if f.Type.Kind() == reflect.Pointer && !omitempty {
+				schema = map[string]any{"allOf": []any{schema}, "nullable": true}
+			}
+			props[name] = schema
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+	}
+	add(t)
+	return map[string]any{"type": "object", "properties": props, "required": required}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// buildOpenAPI builds the OpenAPI 3.0 document of the JSON API.
+func buildOpenAPI() map[string]any {
+	schemas := openAPISchemas{}
+	errorSchema := schemas.ref(reflect.TypeFor[APIError]())
+	paths := map[string]map[string]any{}
+	for _, op := range apiOperations {
+		var parameters []any
+		for _, segment := range strings.Split(op.Path, "/") {
+			name, ok := strings.CutPrefix(segment, "{")
+			if !ok {
+				continue
+			}
+			name = strings.TrimSuffix(name, "}")
+			schema := map[string]any{"type": "string"}
+			switch name {
+			case "version":
+				schema = map[string]any{"type": "integer", "minimum": 1}
+			case "webhook":
+				schema = map[string]any{"type": "integer", "format": "int64", "minimum": 1}
+			case "theme":
+				schema["enum"] = []string{"light", "dark"}
+			}
+			parameters = append(parameters, map[string]any{"name": name, "in": "path", "required": true, "schema": schema})
+		}
+		for _, p := range op.Params {
+			schema := map[string]any{"type": p.Type}
+			if p.Type == "array" {
+				schema["items"] = map[string]any{"type": "string"}
+			}
+			if p.Enum != nil {
+				schema["enum"] = p.Enum
+			}
+			param := map[string]any{"name": p.Name, "in": p.In, "description": p.Description, "schema": schema}
+			if p.Required {
+				param["required"] = true
+			}
+			parameters = append(parameters, param)
+		}
+
+		operation := map[string]any{
+			"operationId":    op.ID,
+			"summary":        op.Summary,
+			"x-role":         op.Role,
+			"responses":      map[string]any{"default": map[string]any{"description": "error", "content": jsonContent(errorSchema)}},
+			"x-root-only":    op.RootOnly,
+			"x-etag-version": op.Versioned,
+		}
+		if op.Description != "" {
+			operation["description"] = op.Description
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		switch {
+		case op.Body != nil:
+			operation["requestBody"] = map[string]any{"required": true, "content": jsonContent(schemas.ref(reflect.TypeOf(op.Body)))}
+		case op.BodyContent == "multipart/form-data":
+			// The form parseImageUpload reads.
+			schemas["ImageUpload"] = map[string]any{
+				"type":     "object",
+				"required": []string{"image"},
+				"properties": map[string]any{
+					"image":   map[string]any{"type": "string", "format": "binary"},
+					"regions": map[string]any{"type": "array", "items": schemas.ref(reflect.TypeFor[ImageRegion]())},
+					"tile":    map[string]any{"type": "integer", "minimum": 8, "maximum": 512},
+				},
+			}
+			operation["requestBody"] = map[string]any{"required": true, "content": map[string]any{op.BodyContent: map[string]any{
+				"schema":   map[string]any{"$ref": "#/components/schemas/ImageUpload"},
+				"encoding": map[string]any{"regions": map[string]any{"contentType": "application/json"}},
+			}}}
+		case op.BodyContent != "":
+			operation["requestBody"] = map[string]any{"required": true, "content": map[string]any{op.BodyContent: map[string]any{
+				"schema": map[string]any{"type": "string", "format": "binary"},
+			}}}
+		}
+
+		response := map[string]any{"description": http.StatusText(op.Status)}
+		switch {
+		case op.Response != nil:
+			response["content"] = jsonContent(schemas.ref(reflect.TypeOf(op.Response)))
+		case op.ResponseContent != "":
+			response["content"] = map[string]any{op.ResponseContent: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+		}
+		if op.Versioned {
+			response["headers"] = map[string]any{"ETag": map[string]any{
+				"description": "the palette's version, quoted",
+				"schema":      map[string]any{"type": "string"},
+			}}
+		}
+		responses := operation["responses"].(map[string]any)
+		for _, code := range append([]int{op.Status}, op.Also...) {
+			r := maps.Clone(response)
+			r["description"] = http.StatusText(code)
+			responses[fmt.Sprint(code)] = r
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+			if op.RootOnly {
+				paths[op.Path]["servers"] = []any{map[string]any{"url": "/"}}
+			}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Contrast Checker API",
+			"version":     "1",
+			"description": "WCAG and APCA contrast results for stored color palettes.",
+		},
+		"servers": []any{
+			map[string]any{"url": "/", "description": "the default project"},
+			map[string]any{
+				"url":         "/projects/{project}",
+				"description": "any other project",
+				"variables":   map[string]any{"project": map[string]any{"default": defaultProject}},
+			},
+		},
+		"security": []any{
+			map[string]any{},
+			map[string]any{"bearer": []string{}},
+			map[string]any{"basic": []string{}},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "an API token or an OIDC ID token"},
+				"basic":  map[string]any{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+var openAPIDocument = sync.OnceValues(func() ([]byte, error) {
+	return json.MarshalIndent(buildOpenAPI(), "", "  ")
+})
+
+// apiOpenAPIHandler serves /api/openapi.json.
+func apiOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		apiError(w, r, "error.render", err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(doc)
+}
+
+// runOpenAPI writes the OpenAPI document, which the Go client in client/
+// is generated from.
+func runOpenAPI(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("o", "", "file to write instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	doc, err := openAPIDocument()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	doc = append(doc, '\n')
+	if *out != "" {
+		err = os.WriteFile(*out, doc, 0o644)
+	} else {
+		_, err = stdout.Write(doc)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
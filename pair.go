@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+)
+
+// PairReport is what the color picker shows for one pair: the WCAG result,
+// the APCA contrast, how the pair looks with color vision deficiencies,
+// and the nearest colors that reach the levels it misses.
+type PairReport struct {
+	ContrastResult
+	APCA        APCAResult      `json:"apca"`
+	Simulations []CVDSimulation `json:"simulations"`
+	Suggestions []Suggestion    `json:"suggestions"`
+}
+
+// APCAResult is the APCA contrast of the foreground as text on the
+// background.
+type APCAResult struct {
+	// Lc is LcRaw truncated to one decimal. It is negative for light text
+	// on a dark background.
+	Lc     float64     `json:"lc"`
+	LcRaw  float64     `json:"lcRaw"`
+	Checks []APCACheck `json:"checks"`
+}
+
+// APCACheck records whether |Lc| meets the level of one kind of content.
+type APCACheck struct {
+	Use      string  `json:"use"`
+	Required float64 `json:"required"`
+	Pass     bool    `json:"pass"`
+}
+
+// apcaUses are the kinds of content the picker checks APCA for, one per
+// level of apcaRequiredLc.
+var apcaUses = []struct {
+	Use    string
+	Target Target
+	SizePx float64
+	Weight int
+}{
+	{"smallText", TargetSmallText, 14, 400},
+	{"bodyText", TargetSmallText, 18, 400},
+	{"contentText", TargetLargeText, 24, 400},
+	{"headline", TargetLargeText, 36, 400},
+	{"nonText", TargetNonText, 0, 0},
+}
+
+func apcaUseNames() []string {
+	var names []string
+	for _, u := range apcaUses {
+		names = append(names, u.Use)
+	}
+	return names
+}
+
+// CVDSimulation is a pair as seen with one color vision deficiency.
+type CVDSimulation struct {
+	Type             string   `json:"type"`
+	ForegroundHex    string   `json:"foregroundHex"`
+	BackgroundHex    string   `json:"backgroundHex"`
+	ContrastRatio    float64  `json:"contrastRatio"`
+	ContrastRatioRaw float64  `json:"contrastRatioRaw"`
+	Category         Category `json:"category"`
+}
+
+// cvdMatrices simulate full dichromacy in linear sRGB (Machado, Oliveira,
+// and Fernandes 2009, severity 1). Achromatopsia keeps only luminance.
+var cvdMatrices = []struct {
+	Type   string
+	Matrix [3][3]float64
+}{
+	{"protanopia", [3][3]float64{
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	}},
+	{"deuteranopia", [3][3]float64{
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	}},
+	{"tritanopia", [3][3]float64{
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	}},
+	{"achromatopsia", [3][3]float64{
+		{0.2126, 0.7152, 0.0722},
+		{0.2126, 0.7152, 0.0722},
+		{0.2126, 0.7152, 0.0722},
+	}},
+}
+
+func cvdTypeNames() []string {
+	var names []string
+	for _, cvd := range cvdMatrices {
+		names = append(names, cvd.Type)
+	}
+	return names
+}
+
+// simulateCVD returns hex as seen through m.
+func simulateCVD(hex string, m [3][3]float64) string {
+	c, _ := parseCSSColor(hex)
+	in := [3]float64{srgbDecode(c.R / 255), srgbDecode(c.G / 255), srgbDecode(c.B / 255)}
+	out := cssColor{A: 1}
+	for i, v := range []*float64{&out.R, &out.G, &out.B} {
+		lin := m[i][0]*in[0] + m[i][1]*in[1] + m[i][2]*in[2]
+		*v = srgbEncode(math.Max(0, math.Min(1, lin))) * 255
+	}
+	return out.hex()
+}
+
+// Suggestion is a replacement for one color of a pair that reaches Level
+// for small text, as close to the original as possible.
+type Suggestion struct {
+	// Role is the color replaced: foreground or background.
+	Role          string  `json:"role"`
+	Level         Level   `json:"level"`
+	Hex           string  `json:"hex"`
+	ContrastRatio float64 `json:"contrastRatio"`
+}
+
+// requiredRatio is the small text ratio a level needs.
+func requiredRatio(level Level) float64 {
+	for _, sc := range successCriteria {
+		for _, t := range sc.Thresholds {
+			if sc.Level == level && t.Target == TargetSmallText {
+				return t.Ratio
+			}
+		}
+	}
+	return math.Inf(1)
+}
+
+// nearestPassing changes the OKLCH lightness of hex, keeping its hue and
+// as much chroma as the gamut allows, until it has the given ratio against
+// other. It tries both darker and lighter and returns the smaller change,
+// or false when neither reaches the ratio.
+func nearestPassing(hex, other string, ratio float64) (string, bool) {
+	start, err := hexToOKLCH(hex)
+	if err != nil {
+		return "", false
+	}
+	if start.C < 1e-4 {
+		// Grays have no meaningful hue; keep them gray.
+		start.C = 0
+	}
+	meets := func(l float64) (string, bool) {
+		candidate := oklch{l, start.C, start.H}.hex()
+		r, err := contrastRatio(candidate, other)
+		return candidate, err == nil && r >= ratio
+	}
+
+	best, bestDelta := "", math.Inf(1)
+	// pass is the end of the range that reaches ratio, if any does, and
+	// fail is the starting lightness, which does not.
+	for _, end := range []float64{0, 1} {
+		if _, ok := meets(end); !ok {
+			continue
+		}
+		pass, fail := end, start.L
+		for i := 0; i < 40; i++ {
+			mid := (pass + fail) / 2
+			if _, ok := meets(mid); ok {
+				pass = mid
+			} else {
+				fail = mid
+			}
+		}
+		if candidate, ok := meets(pass); ok && math.Abs(pass-start.L) < bestDelta {
+			best, bestDelta = candidate, math.Abs(pass-start.L)
+		}
+	}
+	return best, best != ""
+}
+
+// checkPairReport builds the PairReport of a foreground and background,
+// each any opaque CSS color.
+func checkPairReport(fg, bg string) (PairReport, error) {
+	result, err := checkPair(fg, bg)
+	if err != nil {
+		return PairReport{}, err
+	}
+	report := PairReport{ContrastResult: result, Simulations: []CVDSimulation{}, Suggestions: []Suggestion{}}
+	fgHex, bgHex := result.ForegroundHex, result.BackgroundHex
+
+	lc, err := apcaContrast(fgHex, bgHex)
+	if err != nil {
+		return PairReport{}, err
+	}
+	report.APCA = APCAResult{Lc: math.Trunc(lc*10) / 10, LcRaw: lc}
+	for _, u := range apcaUses {
+		required := apcaRequiredLc(u.Target, u.SizePx, u.Weight)
+		report.APCA.Checks = append(report.APCA.Checks, APCACheck{Use: u.Use, Required: required, Pass: math.Abs(lc) >= required})
+	}
+
+	for _, cvd := range cvdMatrices {
+		sim := CVDSimulation{
+			Type:          cvd.Type,
+			ForegroundHex: simulateCVD(fgHex, cvd.Matrix),
+			BackgroundHex: simulateCVD(bgHex, cvd.Matrix),
+		}
+		ratio, err := contrastRatio(sim.ForegroundHex, sim.BackgroundHex)
+		if err != nil {
+			return PairReport{}, err
+		}
+		sim.ContrastRatio, sim.ContrastRatioRaw = DisplayRatio(ratio), ratio
+		sim.Category = resultCategory(complianceLevel(ratio), complianceLevelLarge(ratio))
+		report.Simulations = append(report.Simulations, sim)
+	}
+
+	for _, level := range []Level{LevelAA, LevelAAA} {
+		if result.LevelSmallText >= level {
+			continue
+		}
+		need := requiredRatio(level)
+		for _, role := range []string{"foreground", "background"} {
+			hex, other := fgHex, bgHex
+			if role == "background" {
+				hex, other = bgHex, fgHex
+			}
+			if suggestion, ok := nearestPassing(hex, other, need); ok {
+				ratio, _ := contrastRatio(suggestion, other)
+				report.Suggestions = append(report.Suggestions, Suggestion{Role: role, Level: level, Hex: suggestion, ContrastRatio: DisplayRatio(ratio)})
+			}
+		}
+	}
+	return report, nil
+}
+
+// apiPairHandler serves /api/pair, which checks the foreground and
+// background given as query parameters for the color picker.
+func apiPairHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet)
+		apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	report, err := checkPairReport(q.Get("foreground"), q.Get("background"))
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
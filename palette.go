@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// defaultSteps are the tonal steps generated when a spec does not list any.
+var defaultSteps = []int{50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 950}
+
+// errUnsatisfiable is returned when a valid spec has a requirement that no
+// lightness can meet.
+var errUnsatisfiable = errors.New("unsatisfiable requirement")
+
+// PaletteSpec describes a tonal scale to generate from one seed color.
+type PaletteSpec struct {
+	Seed string `json:"seed"`
+	// Name prefixes the generated color names, as in "brand-500".
+	Name   string      `json:"name,omitempty"`
+	Steps  []int       `json:"steps,omitempty"`
+	Themes []ThemeSpec `json:"themes,omitempty"`
+}
+
+// ThemeSpec is one theme to generate the scale for. In the light theme low
+// steps are light; the dark theme mirrors the scale so low steps are dark.
+type ThemeSpec struct {
+	Theme        string            `json:"theme"`
+	Background   string            `json:"background"`
+	Requirements []StepRequirement `json:"requirements,omitempty"`
+}
+
+// StepRequirement asks for a step to reach a level against a background,
+// which defaults to the theme's background.
+type StepRequirement struct {
+	Step       int    `json:"step"`
+	Level      Level  `json:"level"`
+	Target     Target `json:"target,omitempty"`
+	Background string `json:"background,omitempty"`
+}
+
+// RequirementResult is the checked outcome of one requirement.
+type RequirementResult struct {
+	Theme      string  `json:"theme"`
+	Step       int     `json:"step"`
+	Name       string  `json:"name"`
+	Hex        string  `json:"hex"`
+	Background string  `json:"background"`
+	Target     Target  `json:"target"`
+	Required   Level   `json:"required"`
+	Level      Level   `json:"level"`
+	Ratio      float64 `json:"contrastRatio"`
+	RatioRaw   float64 `json:"contrastRatioRaw"`
+	Pass       bool    `json:"pass"`
+}
+
+// GeneratedPalette is a colors.json-compatible palette together with the
+// report the rest of the tool would produce for it.
+type GeneratedPalette struct {
+	Palette      ColorSets           `json:"palette"`
+	Requirements []RequirementResult `json:"requirements"`
+	Usages       []UsageResult       `json:"usages"`
+	Results      []ContrastResult    `json:"results"`
+}
+
+// oklch is a color in the OKLCH space: perceptual lightness, chroma, and hue
+// in degrees.
+type oklch struct {
+	L, C, H float64
+}
+
+func srgbDecode(c float64) float64 {
+	if c <= srgbThresholdIEC {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func srgbEncode(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func hexToOKLCH(hex string) (oklch, error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 6 {
+		return oklch{}, fmt.Errorf("invalid hex: %s", hex)
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		v, err := parseHex(h[i*2 : i*2+2])
+		if err != nil {
+			return oklch{}, fmt.Errorf("invalid hex: %s", hex)
+		}
+		rgb[i] = srgbDecode(float64(v) / 255)
+	}
+	l := math.Cbrt(0.4122214708*rgb[0] + 0.5363325363*rgb[1] + 0.0514459929*rgb[2])
+	m := math.Cbrt(0.2119034982*rgb[0] + 0.6806995451*rgb[1] + 0.1073969566*rgb[2])
+	s := math.Cbrt(0.0883024619*rgb[0] + 0.2817188376*rgb[1] + 0.6299787005*rgb[2])
+	L := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	a := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	b := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+	hue := math.Atan2(b, a) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return oklch{L: L, C: math.Hypot(a, b), H: hue}, nil
+}
+
+// linearRGB converts c to linear sRGB. The channels fall outside [0, 1]
+// when c is out of gamut.
+func (c oklch) linearRGB() [3]float64 {
+	a := c.C * math.Cos(c.H*math.Pi/180)
+	b := c.C * math.Sin(c.H*math.Pi/180)
+	l := math.Pow(c.L+0.3963377774*a+0.2158037573*b, 3)
+	m := math.Pow(c.L-0.1055613458*a-0.0638541728*b, 3)
+	s := math.Pow(c.L-0.0894841775*a-1.2914855480*b, 3)
+	return [3]float64{
+		4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s,
+	}
+}
+
+func (c oklch) inGamut() bool {
+	const eps = 1e-6
+	for _, v := range c.linearRGB() {
+		if v < -eps || v > 1+eps {
+			return false
+		}
+	}
+	return true
+}
+
+// hex maps c into the sRGB gamut by lowering its chroma, keeping lightness
+// and hue, and formats the result as #rrggbb.
+func (c oklch) hex() string {
+	if !c.inGamut() {
+		lo, hi := 0.0, c.C
+		for i := 0; i < 30; i++ {
+			mid := (lo + hi) / 2
+			if (oklch{c.L, mid, c.H}).inGamut() {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		c.C = lo
+	}
+	rgb := c.linearRGB()
+	out := "#"
+	for _, v := range rgb {
+		v = math.Max(0, math.Min(1, srgbEncode(math.Max(0, v))))
+		out += fmt.Sprintf("%02x", int(math.Round(v*255)))
+	}
+	return out
+}
+
+// stepLightness is the starting OKLCH lightness of a step in the light
+// theme. The dark theme uses the lightness of the mirrored step.
+func stepLightness(step int) float64 {
+	return 0.99 - 0.79*float64(step)/1000
+}
+
+// stepChroma tapers the seed's chroma towards white and black, where
+// saturated colors do not exist.
+func stepChroma(seed oklch, l float64) float64 {
+	peak := 4 * seed.L * (1 - seed.L)
+	if peak == 0 {
+		return 0
+	}
+	return seed.C * math.Min(1, 4*l*(1-l)/peak)
+}
+
+func (r StepRequirement) target() Target {
+	if r.Target == "" {
+		return TargetSmallText
+	}
+	return r.Target
+}
+
+// normalize fills in defaults and rejects specs that cannot be generated.
+func (spec *PaletteSpec) normalize() error {
+	if _, err := hexToOKLCH(spec.Seed); err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+	spec.Seed = "#" + strings.ToLower(strings.TrimPrefix(spec.Seed, "#"))
+	if spec.Name == "" {
+		spec.Name = "brand"
+	}
+	if len(spec.Steps) == 0 {
+		spec.Steps = defaultSteps
+	}
+	spec.Steps = append([]int(nil), spec.Steps...)
+	sort.Ints(spec.Steps)
+	for i, step := range spec.Steps {
+		if step <= 0 || step >= 1000 {
+			return fmt.Errorf("step %d: must be between 1 and 999", step)
+		}
+		if i > 0 && spec.Steps[i-1] == step {
+			return fmt.Errorf("step %d: listed twice", step)
+		}
+	}
+	if len(spec.Themes) == 0 {
+		spec.Themes = []ThemeSpec{
+			{Theme: "light", Background: "#ffffff"},
+			{Theme: "dark", Background: "#121212"},
+		}
+	}
+	spec.Themes = append([]ThemeSpec(nil), spec.Themes...)
+	seen := map[string]bool{}
+	for i := range spec.Themes {
+		theme := &spec.Themes[i]
+		theme.Requirements = append([]StepRequirement(nil), theme.Requirements...)
+		if theme.Theme != "light" && theme.Theme != "dark" {
+			return fmt.Errorf("theme %q: must be light or dark", theme.Theme)
+		}
+		if seen[theme.Theme] {
+			return fmt.Errorf("theme %q: listed twice", theme.Theme)
+		}
+		seen[theme.Theme] = true
+		if _, err := relativeLuminance(theme.Background); err != nil {
+			return fmt.Errorf("theme %q background: %w", theme.Theme, err)
+		}
+		for j := range theme.Requirements {
+			req := &theme.Requirements[j]
+			if stepIndex(spec.Steps, req.Step) < 0 {
+				return fmt.Errorf("theme %q: requirement for unknown step %d", theme.Theme, req.Step)
+			}
+			if req.Level == LevelFail {
+				return fmt.Errorf("theme %q step %d: level must be AA or AAA", theme.Theme, req.Step)
+			}
+			switch req.target() {
+			case TargetSmallText, TargetLargeText, TargetNonText:
+			default:
+				return fmt.Errorf("theme %q step %d: invalid target %q", theme.Theme, req.Step, req.Target)
+			}
+			if req.target() == TargetNonText && req.Level == LevelAAA {
+				return fmt.Errorf("theme %q step %d: non-text contrast has no AAA level", theme.Theme, req.Step)
+			}
+			if req.Background == "" {
+				req.Background = theme.Background
+			}
+			if _, err := relativeLuminance(req.Background); err != nil {
+				return fmt.Errorf("theme %q step %d background: %w", theme.Theme, req.Step, err)
+			}
+		}
+	}
+	return nil
+}
+
+// stepIndex returns the index of step in the sorted steps, or -1.
+func stepIndex(steps []int, step int) int {
+	i := sort.SearchInts(steps, step)
+	if i == len(steps) || steps[i] != step {
+		return -1
+	}
+	return i
+}
+
+// meets reports whether hex reaches the requirement, using the same
+// unrounded comparison as the rest of the engine.
+func (r StepRequirement) meets(hex string) bool {
+	ratio, err := contrastRatio(hex, r.Background)
+	return err == nil && levelFor(r.target(), ratio) >= r.Level
+}
+
+// GeneratePalette builds the tonal scale for every theme in spec, moving the
+// lightness of required steps just far enough from their background to
+// reach the required level. It fails if a requirement cannot be met.
+func GeneratePalette(ctx context.Context, spec PaletteSpec) (*GeneratedPalette, error) {
+	if err := spec.normalize(); err != nil {
+		return nil, err
+	}
+	seed, _ := hexToOKLCH(spec.Seed)
+
+	// In the light theme the step closest to the seed's lightness is the
+	// seed itself, unless a requirement moves it.
+	seedIdx := 0
+	for i, step := range spec.Steps {
+		if math.Abs(stepLightness(step)-seed.L) < math.Abs(stepLightness(spec.Steps[seedIdx])-seed.L) {
+			seedIdx = i
+		}
+	}
+
+	out := &GeneratedPalette{Palette: ColorSets{Light: map[string]string{}, Dark: map[string]string{}}}
+	for _, theme := range spec.Themes {
+		lightness := make([]float64, len(spec.Steps))
+		for i, step := range spec.Steps {
+			if theme.Theme == "dark" {
+				step = 1000 - step
+			}
+			lightness[i] = stepLightness(step)
+		}
+		if theme.Theme == "light" {
+			lightness[seedIdx] = seed.L
+		}
+		colorAt := func(l float64) oklch {
+			return oklch{L: l, C: stepChroma(seed, l), H: seed.H}
+		}
+		hexAt := func(i int) string {
+			if theme.Theme == "light" && i == seedIdx && lightness[i] == seed.L {
+				return spec.Seed
+			}
+			return colorAt(lightness[i]).hex()
+		}
+
+		for _, req := range theme.Requirements {
+			i := stepIndex(spec.Steps, req.Step)
+			if req.meets(hexAt(i)) {
+				continue
+			}
+			// Search darker and lighter for the smallest change that
+			// reaches the level. Usually only the direction away from the
+			// background does, but a mid-luminance background can allow
+			// both.
+			end, hi := -1.0, 0.0
+			for _, candidate := range []float64{0, 1} {
+				if !req.meets(colorAt(candidate).hex()) {
+					continue
+				}
+				fail, pass := lightness[i], candidate
+				for n := 0; n < 40; n++ {
+					mid := (fail + pass) / 2
+					if req.meets(colorAt(mid).hex()) {
+						pass = mid
+					} else {
+						fail = mid
+					}
+				}
+				if end < 0 || math.Abs(pass-lightness[i]) < math.Abs(hi-lightness[i]) {
+					end, hi = candidate, pass
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s-%d cannot reach %s for %s against %s in the %s theme",
+					errUnsatisfiable, spec.Name, req.Step, req.Level, req.target(), req.Background, theme.Theme)
+			}
+			lightness[i] = hi
+
+			// Keep the scale ordered: steps on the far side of the moved
+			// one must be at least as dark, or as light, as it now is.
+			for j := range lightness {
+				darkerSide := (j > i) == (theme.Theme == "light")
+				if end == 0 && darkerSide && lightness[j] > hi {
+					lightness[j] = hi
+				}
+				if end == 1 && !darkerSide && lightness[j] < hi {
+					lightness[j] = hi
+				}
+			}
+		}
+
+		colors := out.Palette.Light
+		if theme.Theme == "dark" {
+			colors = out.Palette.Dark
+		}
+		for i, step := range spec.Steps {
+			colors[fmt.Sprintf("%s-%d", spec.Name, step)] = hexAt(i)
+		}
+
+		for _, req := range theme.Requirements {
+			name := fmt.Sprintf("%s-%d", spec.Name, req.Step)
+			hex := colors[name]
+			ratio, _ := contrastRatio(hex, req.Background)
+			level := levelFor(req.target(), ratio)
+			if level < req.Level {
+				return nil, fmt.Errorf("%w: %s cannot reach %s for %s against %s in the %s theme together with the other requirements",
+					errUnsatisfiable, name, req.Level, req.target(), req.Background, theme.Theme)
+			}
+			out.Requirements = append(out.Requirements, RequirementResult{
+				Theme:      theme.Theme,
+				Step:       req.Step,
+				Name:       name,
+				Hex:        hex,
+				Background: req.Background,
+				Target:     req.target(),
+				Required:   req.Level,
+				Level:      level,
+				Ratio:      DisplayRatio(ratio),
+				RatioRaw:   ratio,
+				Pass:       true,
+			})
+
+			// Record the requirement as a usage so the palette keeps being
+			// checked once it is saved as colors.json. Usage foregrounds
+			// are looked up in the light palette, so dark colors use hex.
+			fg := name
+			if theme.Theme == "dark" {
+				fg = hex
+			}
+			rule := UsageRule{
+				Name:       fmt.Sprintf("%s on %s (%s)", name, req.Background, theme.Theme),
+				Foreground: fg,
+				Background: req.Background,
+			}
+			switch req.target() {
+			case TargetNonText:
+				rule.NonText = true
+			case TargetLargeText:
+				rule.FontSize = "24px"
+			default:
+				rule.FontSize = "16px"
+			}
+			out.Palette.Usages = append(out.Palette.Usages, rule)
+		}
+	}
+
+	out.Usages, _ = evaluateUsages(ctx, &out.Palette, AlgorithmWCAG)
+	out.Results, _ = contrastResults(ctx, &out.Palette)
+	return out, nil
+}
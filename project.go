@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Project groups the palettes of one product with the settings used to
+// judge them.
+type Project struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Settings  ProjectSettings `json:"settings"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// ProjectSettings are a project's defaults and the thresholds its check
+// is held to.
+type ProjectSettings struct {
+	// Palette is the palette used when a request names none, defaultPalette
+	// unless given.
+	Palette string `json:"palette,omitempty"`
+	// Algorithm judges usage rules when a request names none.
+	Algorithm string `json:"algorithm,omitempty"`
+	// MaxFailingUsages is how many usage rules may fail the check.
+	MaxFailingUsages int `json:"maxFailingUsages"`
+	// MaxFailPairs, when set, is how many pairs may fall in the Fail
+	// category. Most palettes have pairs nobody uses together, so it is
+	// not checked by default.
+	MaxFailPairs *int `json:"maxFailPairs,omitempty"`
+	// AllowWarnings lets the check pass with invalid colors or usage rules.
+	AllowWarnings bool `json:"allowWarnings,omitempty"`
+}
+
+// palette returns the palette a project uses by default.
+func (p Project) palette() string {
+	if p.Settings.Palette != "" {
+		return p.Settings.Palette
+	}
+	return defaultPalette
+}
+
+// base is the path prefix of the project's pages and API.
+func (p Project) base() string {
+	if p.ID == "" || p.ID == defaultProject {
+		return ""
+	}
+	return "/projects/" + p.ID
+}
+
+var projectIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// validateProject checks a project, naming it after its ID when it has no
+// name.
+func validateProject(p *Project) error {
+	if !projectIDPattern.MatchString(p.ID) {
+		return fmt.Errorf("%w: id %q must be lowercase letters, digits, and dashes", errInvalidProject, p.ID)
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		p.Name = p.ID
+	}
+	s := p.Settings
+	if s.Palette != "" {
+		if err := validName(s.Palette); err != nil {
+			return fmt.Errorf("%w: palette: %w", errInvalidProject, err)
+		}
+	}
+	switch s.Algorithm {
+	case "", AlgorithmWCAG, AlgorithmAPCA:
+	default:
+		return fmt.Errorf("%w: algorithm %q must be %s or %s", errInvalidProject, s.Algorithm, AlgorithmWCAG, AlgorithmAPCA)
+	}
+	if s.MaxFailingUsages < 0 || s.MaxFailPairs != nil && *s.MaxFailPairs < 0 {
+		return fmt.Errorf("%w: thresholds must not be negative", errInvalidProject)
+	}
+	return nil
+}
+
+func getProject(ctx context.Context, q querier, id string) (Project, error) {
+	p := Project{ID: id}
+	var settings, created string
+	err := q.QueryRowContext(ctx, "SELECT name, settings, created_at FROM projects WHERE id = ?", id).
+		Scan(&p.Name, &settings, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, fmt.Errorf("project %q: %w", id, errNotFound)
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(settings), &p.Settings); err != nil {
+		return p, fmt.Errorf("project %q settings: %w", id, err)
+	}
+	p.CreatedAt, err = time.Parse(time.RFC3339Nano, created)
+	return p, err
+}
+
+// Project returns the project with the given ID, or errNotFound.
+func (s *Store) Project(ctx context.Context, id string) (Project, error) {
+	return getProject(ctx, s.db, id)
+}
+
+// Projects returns every project, by ID.
+func (s *Store) Projects(ctx context.Context) ([]Project, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM projects ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	projects := []Project{}
+	for _, id := range ids {
+		p, err := s.Project(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+// SaveProject creates or updates a project. With create set it fails with
+// errProjectExists rather than update one.
+func (s *Store) SaveProject(ctx context.Context, p Project, create bool) (Project, bool, error) {
+	if err := validateProject(&p); err != nil {
+		return p, false, err
+	}
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return p, false, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return p, false, err
+	}
+	defer tx.Rollback()
+	old, err := getProject(ctx, tx, p.ID)
+	created := errors.Is(err, errNotFound)
+	switch {
+	case created:
+		p.CreatedAt = time.Now().UTC()
+	case err != nil:
+		return p, false, err
+	case create:
+		return p, false, errProjectExists
+	default:
+		p.CreatedAt = old.CreatedAt
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO projects (id, name, settings, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, settings = excluded.settings`,
+		p.ID, p.Name, string(settings), p.CreatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return p, false, err
+	}
+	return p, created, tx.Commit()
+}
+
+// DeleteProject removes a project with its palettes and their history.
+// The default project cannot be deleted.
+func (s *Store) DeleteProject(ctx context.Context, id string) error {
+	if id == defaultProject {
+		return fmt.Errorf("%w: the default project cannot be deleted", errInvalidProject)
+	}
+	res, err := s.db.ExecContext(ctx, "DELETE FROM projects WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	return fmt.Errorf("project %q: %w", id, errNotFound)
+}
+
+// requestProject returns the project named by the {project} path value, or
+// the default project.
+func requestProject(r *http.Request) (Project, error) {
+	return findProject(r.Context(), r.PathValue("project"))
+}
+
+// findProject returns the project with the given ID, or the default project
+// when id is empty. Without a store only the default project exists, with
+// no settings.
+func findProject(ctx context.Context, id string) (Project, error) {
+	if store == nil {
+		if id != "" && id != defaultProject {
+			return Project{}, fmt.Errorf("project %q: %w (start the server with -db to use projects)", id, errNotFound)
+		}
+		return Project{ID: defaultProject}, nil
+	}
+	if id == "" {
+		id = defaultProject
+	}
+	return store.Project(ctx, id)
+}
+
+// requestResultQuery parses the result query of a request, taking the
+// usage algorithm from its project when the request names none.
+// Handlers that go on to load the palette report a bad project there.
+func requestResultQuery(r *http.Request) (ResultQuery, error) {
+	project, _ := requestProject(r)
+	return projectResultQuery(r.URL.Query(), project)
+}
+
+// projectResultQuery parses v, taking the usage algorithm from project when
+// v names none.
+func projectResultQuery(v url.Values, project Project) (ResultQuery, error) {
+	if v.Get("algorithm") == "" && project.Settings.Algorithm != "" {
+		v.Set("algorithm", project.Settings.Algorithm)
+	}
+	return parseResultQuery(v)
+}
+
+// ProjectCheck is the outcome of checking a palette against its project's
+// thresholds.
+type ProjectCheck struct {
+	Project string `json:"project"`
+	Palette string `json:"palette"`
+	Version int    `json:"version"`
+	Pass    bool   `json:"pass"`
+	// Problems says why the check failed, one threshold per entry.
+	Problems      []string         `json:"problems"`
+	Totals        LevelCounts      `json:"totals"`
+	FailingUsages []UsageResult    `json:"failingUsages"`
+	Warnings      []PaletteWarning `json:"warnings"`
+	UsageWarnings []PaletteWarning `json:"usageWarnings"`
+	Settings      ProjectSettings  `json:"settings"`
+	Algorithm     string           `json:"algorithm"`
+}
+
+// checkProject holds a palette to the thresholds of its project.
+func checkProject(ctx context.Context, project Project, palette StoredPalette, algorithm string) ProjectCheck {
+	s := project.Settings
+	results, warnings := contrastResults(ctx, &palette.ColorSets)
+	levels := bucketResults(results)
+	usages, usageWarnings := evaluateUsages(ctx, &palette.ColorSets, algorithm)
+	c := ProjectCheck{
+		Project: project.ID,
+		Palette: palette.Name,
+		Version: palette.Version,
+		Totals: LevelCounts{
+			AAA:     len(levels.AAA),
+			AA:      len(levels.AA),
+			AALarge: len(levels.AALarge),
+			Fail:    len(levels.Fail),
+		},
+		Problems:      []string{},
+		FailingUsages: []UsageResult{},
+		Warnings:      warnings,
+		UsageWarnings: usageWarnings,
+		Settings:      s,
+		Algorithm:     algorithm,
+	}
+	if c.Warnings == nil {
+		c.Warnings = []PaletteWarning{}
+	}
+	if c.UsageWarnings == nil {
+		c.UsageWarnings = []PaletteWarning{}
+	}
+	for _, u := range usages {
+		if !u.Pass {
+			c.FailingUsages = append(c.FailingUsages, u)
+		}
+	}
+
+	if n := len(c.FailingUsages); n > s.MaxFailingUsages {
+		c.Problems = append(c.Problems, fmt.Sprintf("%d of %d usage rules fail; %d allowed", n, len(usages), s.MaxFailingUsages))
+	}
+	if s.MaxFailPairs != nil && c.Totals.Fail > *s.MaxFailPairs {
+		c.Problems = append(c.Problems, fmt.Sprintf("%d pairs in Fail; %d allowed", c.Totals.Fail, *s.MaxFailPairs))
+	}
+	if n := len(c.Warnings) + len(c.UsageWarnings); n > 0 && !s.AllowWarnings {
+		c.Problems = append(c.Problems, fmt.Sprintf("invalid colors or usage rules: %d", n))
+	}
+	c.Pass = len(c.Problems) == 0
+	return c
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FilterExpr is a parsed search expression such as
+//
+//	fg:red* bg:#1212* ratio<4.5 large:AA requiresFix:true theme:dark:gray*
+//
+// Terms are separated by spaces and must all match. A leading "-" negates a
+// term and double quotes keep spaces inside a value. Supported terms:
+//
+//	word            substring of either color's name or hex value
+//	fg:PATTERN      foreground name or hex; * and ? are wildcards
+//	bg:PATTERN      background name or hex
+//	name:PATTERN    either color's name or hex
+//	ratio OP N      raw contrast ratio compared with <, <=, >, >=, =, != or :
+//	small:LEVELS    small-text level, e.g. small:AA or small:AA,AAA
+//	large:LEVELS    large-text level
+//	nontext:LEVELS  non-text (UI component) level, AA or Fail
+//	category:NAMES  AAA, AA, AALarge, or Fail, as grouped on the page
+//	pass:SC         pairs that meet every threshold of a success criterion,
+//	                e.g. pass:1.4.6, or one threshold, e.g. pass:1.4.3:largeText
+//	fail:SC         pairs that miss any threshold of a success criterion, or
+//	                the given one
+//	requiresFix:B   true or false
+//	theme:T:PATTERN the color the light or dark theme supplies, that is the
+//	                foreground or background, e.g. theme:dark:gray*
+type FilterExpr struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	negate bool
+	match  func(ContrastResult) bool
+}
+
+// Match reports whether result satisfies every term. A nil expression
+// matches everything.
+func (e *FilterExpr) Match(result ContrastResult) bool {
+	if e == nil {
+		return true
+	}
+	for _, t := range e.terms {
+		if t.match(result) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFilter parses a search expression. An empty string yields a nil
+// expression.
+func ParseFilter(s string) (*FilterExpr, error) {
+	tokens, err := splitFilterTokens(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	expr := &FilterExpr{}
+	for _, tok := range tokens {
+		negate := false
+		if len(tok) > 1 && tok[0] == '-' {
+			negate = true
+			tok = tok[1:]
+		}
+		match, err := parseFilterTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		expr.terms = append(expr.terms, filterTerm{negate: negate, match: match})
+	}
+	return expr, nil
+}
+
+func splitFilterTokens(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuotes, started := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			started = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func parseFilterTerm(tok string) (func(ContrastResult) bool, error) {
+	if len(tok) > len("ratio") && strings.EqualFold(tok[:len("ratio")], "ratio") &&
+		strings.ContainsRune("<>=!:", rune(tok[len("ratio")])) {
+		return parseRatioTerm(tok)
+	}
+
+	key, value, found := strings.Cut(tok, ":")
+	if !found {
+		word := strings.ToLower(tok)
+		return func(r ContrastResult) bool {
+			return strings.Contains(strings.ToLower(r.ForegroundName), word) ||
+				strings.Contains(strings.ToLower(r.BackgroundName), word) ||
+				strings.Contains(strings.ToLower(r.ForegroundHex), word) ||
+				strings.Contains(strings.ToLower(r.BackgroundHex), word)
+		}, nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %q", key+":")
+	}
+
+	switch strings.ToLower(key) {
+	case "fg":
+		pattern, err := globPattern(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool {
+			return globMatch(pattern, r.ForegroundName) || globMatch(pattern, r.ForegroundHex)
+		}, nil
+	case "bg":
+		pattern, err := globPattern(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool {
+			return globMatch(pattern, r.BackgroundName) || globMatch(pattern, r.BackgroundHex)
+		}, nil
+	case "name":
+		pattern, err := globPattern(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool {
+			return globMatch(pattern, r.ForegroundName) || globMatch(pattern, r.ForegroundHex) ||
+				globMatch(pattern, r.BackgroundName) || globMatch(pattern, r.BackgroundHex)
+		}, nil
+	case "small":
+		levels, err := parseLevelList(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool { return levels[r.LevelSmallText] }, nil
+	case "large":
+		levels, err := parseLevelList(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool { return levels[r.LevelLargeText] }, nil
+	case "nontext":
+		levels, err := parseLevelList(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool { return levels[r.LevelNonText] }, nil
+	case "category":
+		categories := map[Category]bool{}
+		for _, s := range strings.Split(value, ",") {
+			c, err := ParseCategory(s)
+			if err != nil {
+				return nil, err
+			}
+			categories[c] = true
+		}
+		return func(r ContrastResult) bool { return categories[r.Category] }, nil
+	case "pass", "fail":
+		id, target, _ := strings.Cut(value, ":")
+		if !knownCriterion(id) {
+			return nil, fmt.Errorf("unknown success criterion %q", id)
+		}
+		if target != "" && !criterionHasTarget(id, Target(target)) {
+			return nil, fmt.Errorf("success criterion %s has no %q threshold", id, target)
+		}
+		// Without a target, pass needs every threshold met and fail any
+		// threshold missed.
+		want := strings.EqualFold(key, "pass")
+		return func(r ContrastResult) bool {
+			for _, c := range r.Criteria {
+				if c.ID != id {
+					continue
+				}
+				matched := want
+				for _, check := range c.Checks {
+					if target == "" || check.Target == Target(target) {
+						if want {
+							matched = matched && check.Pass
+						} else {
+							matched = matched || !check.Pass
+						}
+					}
+				}
+				return matched
+			}
+			return false
+		}, nil
+	case "requiresfix":
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requiresFix value %q", value)
+		}
+		return func(r ContrastResult) bool { return r.RequiresFix == want }, nil
+	case "theme":
+		theme, glob, _ := strings.Cut(value, ":")
+		theme = strings.ToLower(theme)
+		if theme != "light" && theme != "dark" {
+			return nil, fmt.Errorf("unknown theme %q", theme)
+		}
+		// Every pair has one color from each theme, so the theme alone
+		// would match everything.
+		if glob == "" {
+			return nil, fmt.Errorf("theme:%s needs a pattern, e.g. theme:%s:gray*", theme, theme)
+		}
+		pattern, err := globPattern(glob)
+		if err != nil {
+			return nil, err
+		}
+		return func(r ContrastResult) bool {
+			if strings.EqualFold(r.ForegroundTheme, theme) && (globMatch(pattern, r.ForegroundName) || globMatch(pattern, r.ForegroundHex)) {
+				return true
+			}
+			return strings.EqualFold(r.BackgroundTheme, theme) && (globMatch(pattern, r.BackgroundName) || globMatch(pattern, r.BackgroundHex))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q", key)
+	}
+}
+
+func parseRatioTerm(tok string) (func(ContrastResult) bool, error) {
+	rest := tok[len("ratio"):]
+	var op string
+	for _, candidate := range []string{"<=", ">=", "!=", "<", ">", "=", ":"} {
+		if strings.HasPrefix(rest, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid ratio comparison %q", tok)
+	}
+	n, err := strconv.ParseFloat(rest[len(op):], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ratio value in %q", tok)
+	}
+
+	var cmp func(float64) bool
+	switch op {
+	case "<":
+		cmp = func(v float64) bool { return v < n }
+	case "<=":
+		cmp = func(v float64) bool { return v <= n }
+	case ">":
+		cmp = func(v float64) bool { return v > n }
+	case ">=":
+		cmp = func(v float64) bool { return v >= n }
+	case "!=":
+		cmp = func(v float64) bool { return v != n }
+	default:
+		cmp = func(v float64) bool { return v == n }
+	}
+	return func(r ContrastResult) bool { return cmp(r.ContrastRatioRaw) }, nil
+}
+
+func parseLevelList(value string) (map[Level]bool, error) {
+	levels := map[Level]bool{}
+	for _, s := range strings.Split(value, ",") {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return nil, err
+		}
+		levels[level] = true
+	}
+	return levels, nil
+}
+
+func criterionHasTarget(id string, target Target) bool {
+	for _, sc := range successCriteria {
+		for _, t := range sc.Thresholds {
+			if sc.ID == id && t.Target == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func knownCriterion(id string) bool {
+	for _, sc := range successCriteria {
+		if sc.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func globPattern(value string) (string, error) {
+	pattern := strings.ToLower(value)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", fmt.Errorf("invalid pattern %q", value)
+	}
+	return pattern, nil
+}
+
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(pattern, strings.ToLower(s))
+	return ok
+}
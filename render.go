@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Colors used to mark categories in rendered images, matching the page.
+var categoryColors = map[Category]string{
+	CategoryAAA:     "#1e8e3e",
+	CategoryAA:      "#7cb342",
+	CategoryAALarge: "#f39c12",
+	CategoryFail:    "#d93025",
+}
+
+// levelColors mark a single level the same way as the matching category.
+var levelColors = map[Level]string{
+	LevelAAA:  categoryColors[CategoryAAA],
+	LevelAA:   categoryColors[CategoryAA],
+	LevelFail: categoryColors[CategoryFail],
+}
+
+// imageFonts are the fonts images can be rendered with. They are the Go
+// fonts, so rendering needs no system fonts.
+var imageFonts = map[string][]byte{
+	"regular": goregular.TTF,
+	"bold":    gobold.TTF,
+	"mono":    gomono.TTF,
+}
+
+// svgFonts is how each image font is requested in SVG output.
+var svgFonts = map[string]string{
+	"regular": `font-family="Go, sans-serif"`,
+	"bold":    `font-family="Go, sans-serif" font-weight="bold"`,
+	"mono":    `font-family="Go Mono, monospace"`,
+}
+
+var (
+	parsedFontsMu sync.Mutex
+	parsedFonts   = map[string]*opentype.Font{}
+)
+
+// fontFace returns the named image font at sizePx pixels.
+func fontFace(name string, sizePx float64) (font.Face, error) {
+	parsedFontsMu.Lock()
+	defer parsedFontsMu.Unlock()
+	f, ok := parsedFonts[name]
+	if !ok {
+		data, known := imageFonts[name]
+		if !known {
+			return nil, fmt.Errorf("unknown font %q", name)
+		}
+		var err error
+		f, err = opentype.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		parsedFonts[name] = f
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: sizePx, DPI: 72, Hinting: font.HintingFull})
+}
+
+// textWidth measures s in the named image font, for laying out both SVG and
+// PNG output.
+func textWidth(fontName string, sizePx float64, s string) int {
+	face, err := fontFace(fontName, sizePx)
+	if err != nil {
+		return 0
+	}
+	defer face.Close()
+	return font.MeasureString(face, s).Ceil()
+}
+
+// hexRGBA parses a #rrggbb color.
+func hexRGBA(hex string) (color.RGBA, error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid hex: %s", hex)
+	}
+	var c [3]uint8
+	for i := range c {
+		v, err := parseHex(h[i*2 : i*2+2])
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid hex: %s", hex)
+		}
+		c[i] = uint8(v)
+	}
+	return color.RGBA{c[0], c[1], c[2], 0xff}, nil
+}
+
+// inkFor picks black or white, whichever contrasts more with hex.
+func inkFor(hex string) string {
+	black, _ := contrastRatio("#000000", hex)
+	white, _ := contrastRatio("#ffffff", hex)
+	if white > black {
+		return "#ffffff"
+	}
+	return "#000000"
+}
+
+// textStyle is how a canvas draws a piece of text.
+type textStyle struct {
+	Font string
+	Size float64
+	// End anchors the text at its end instead of its start.
+	End bool
+}
+
+// canvas is a drawing surface with the few operations the image exports
+// need, so each export is laid out once for both SVG and PNG.
+type canvas interface {
+	rect(r image.Rectangle, hex string)
+	// text draws s with its baseline at y.
+	text(x, y int, style textStyle, hex, s string)
+	// clip runs paint with drawing limited to r. title describes the
+	// region where the format supports it.
+	clip(r image.Rectangle, title string, paint func())
+	encode(w io.Writer) error
+}
+
+// imageContentTypes are the formats renderImage supports.
+var imageContentTypes = map[string]string{
+	"svg": "image/svg+xml",
+	"png": "image/png",
+}
+
+// maxImagePixels bounds rendered images, as a PNG canvas takes four bytes
+// a pixel: 25 million pixels is 100 MB.
+const maxImagePixels = 25_000_000
+
+var errImageTooLarge = errors.New("image too large")
+
+// checkImageSize rejects images of more than maxImagePixels.
+func checkImageSize(width, height int) error {
+	if int64(width)*int64(height) > maxImagePixels {
+		return fmt.Errorf("%w: %dx%d is more than %d pixels; narrow the search",
+			errImageTooLarge, width, height, maxImagePixels)
+	}
+	return nil
+}
+
+// renderImage paints an image of the given size in format "svg" or "png".
+func renderImage(w io.Writer, format string, width, height int, paint func(canvas)) error {
+	if err := checkImageSize(width, height); err != nil {
+		return err
+	}
+	var c canvas
+	switch format {
+	case "svg":
+		c = newSVGCanvas(width, height)
+	case "png":
+		c = newPNGCanvas(width, height)
+	default:
+		return fmt.Errorf("unknown image format %q", format)
+	}
+	paint(c)
+	return c.encode(w)
+}
+
+type svgCanvas struct {
+	buf bytes.Buffer
+	// origins are the offsets of the nested clip regions.
+	origins []image.Point
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := &svgCanvas{origins: []image.Point{{}}}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	return c
+}
+
+func (c *svgCanvas) local(p image.Point) image.Point {
+	return p.Sub(c.origins[len(c.origins)-1])
+}
+
+func (c *svgCanvas) rect(r image.Rectangle, hex string) {
+	p := c.local(r.Min)
+	fmt.Fprintf(&c.buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", p.X, p.Y, r.Dx(), r.Dy(), hex)
+}
+
+func (c *svgCanvas) text(x, y int, style textStyle, hex, s string) {
+	p := c.local(image.Pt(x, y))
+	anchor := ""
+	if style.End {
+		anchor = ` text-anchor="end"`
+	}
+	fmt.Fprintf(&c.buf, `<text x="%d" y="%d" %s font-size="%g" fill="%s"%s>%s</text>`+"\n",
+		p.X, p.Y, svgFonts[style.Font], style.Size, hex, anchor, html.EscapeString(s))
+}
+
+// clip uses a nested svg element, which clips its content to its viewport.
+func (c *svgCanvas) clip(r image.Rectangle, title string, paint func()) {
+	p := c.local(r.Min)
+	fmt.Fprintf(&c.buf, `<svg x="%d" y="%d" width="%d" height="%d">`+"\n", p.X, p.Y, r.Dx(), r.Dy())
+	if title != "" {
+		fmt.Fprintf(&c.buf, "<title>%s</title>\n", html.EscapeString(title))
+	}
+	c.origins = append(c.origins, r.Min)
+	paint()
+	c.origins = c.origins[:len(c.origins)-1]
+	c.buf.WriteString("</svg>\n")
+}
+
+func (c *svgCanvas) encode(w io.Writer) error {
+	c.buf.WriteString("</svg>\n")
+	_, err := c.buf.WriteTo(w)
+	return err
+}
+
+type pngCanvas struct {
+	img   *image.RGBA
+	dst   draw.Image
+	faces map[textStyle]font.Face
+	err   error
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	return &pngCanvas{img: img, dst: img, faces: map[textStyle]font.Face{}}
+}
+
+func (c *pngCanvas) rect(r image.Rectangle, hex string) {
+	col, err := hexRGBA(hex)
+	if err != nil {
+		c.err = err
+		return
+	}
+	draw.Draw(c.dst, r.Intersect(c.dst.Bounds()), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+func (c *pngCanvas) text(x, y int, style textStyle, hex, s string) {
+	col, err := hexRGBA(hex)
+	if err != nil {
+		c.err = err
+		return
+	}
+	key := textStyle{Font: style.Font, Size: style.Size}
+	face, ok := c.faces[key]
+	if !ok {
+		face, err = fontFace(style.Font, style.Size)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.faces[key] = face
+	}
+	if style.End {
+		x -= font.MeasureString(face, s).Ceil()
+	}
+	d := font.Drawer{Dst: c.dst, Src: image.NewUniform(col), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+func (c *pngCanvas) clip(r image.Rectangle, title string, paint func()) {
+	prev := c.dst
+	c.dst = c.img.SubImage(r.Intersect(prev.Bounds())).(*image.RGBA)
+	paint()
+	c.dst = prev
+}
+
+func (c *pngCanvas) encode(w io.Writer) error {
+	for _, face := range c.faces {
+		face.Close()
+	}
+	if c.err != nil {
+		return c.err
+	}
+	return png.Encode(w, c.img)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// ResultQuery holds the search, filter, sort, and paging options shared by
+// the HTML view, the JSON API, and the query command.
+type ResultQuery struct {
+	Search string
+	Expr   *FilterExpr
+	Filter string
+	// FilterCategory is the parsed Filter; it is ignored when Filter is empty.
+	FilterCategory Category
+	Sort           string
+	Order          string
+	Page           int
+	Limit          int
+	// Algorithm judges usage rules: AlgorithmWCAG or AlgorithmAPCA.
+	Algorithm string
+	// View is how the page shows results: "list" or "matrix".
+	View string
+}
+
+// LevelCounts is the number of results in each category before paging.
+type LevelCounts struct {
+	AAA     int `json:"AAA"`
+	AA      int `json:"AA"`
+	AALarge int `json:"AALarge"`
+	Fail    int `json:"Fail"`
+}
+
+// ResultPage is one page of sorted results together with the totals of the
+// whole filtered set.
+type ResultPage struct {
+	Results []ContrastResult `json:"results"`
+	Totals  LevelCounts      `json:"totals"`
+	Total   int              `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+	Pages   int              `json:"pages"`
+	Sort    string           `json:"sort"`
+	Order   string           `json:"order"`
+	// SRGBThreshold is the linearization threshold the ratios were computed
+	// with.
+	SRGBThreshold float64          `json:"srgbThreshold"`
+	Warnings      []PaletteWarning `json:"warnings"`
+}
+
+var sortFields = map[string]func(a, b ContrastResult) int{
+	"foreground": func(a, b ContrastResult) int { return strings.Compare(a.ForegroundName, b.ForegroundName) },
+	"background": func(a, b ContrastResult) int { return strings.Compare(a.BackgroundName, b.BackgroundName) },
+	"ratio":      func(a, b ContrastResult) int { return compareFloat(a.ContrastRatioRaw, b.ContrastRatioRaw) },
+	"level": func(a, b ContrastResult) int {
+		if c := int(a.Category) - int(b.Category); c != 0 {
+			return c
+		}
+		return compareFloat(a.ContrastRatioRaw, b.ContrastRatioRaw)
+	},
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseResultQuery reads q, filter, sort, order, page, limit, algorithm, and
+// view from the query string, applying defaults for anything left out. search is
+// accepted as an older name for q.
+func parseResultQuery(v url.Values) (ResultQuery, error) {
+	q := ResultQuery{
+		Search:    v.Get("q"),
+		Filter:    strings.ToUpper(v.Get("filter")),
+		Sort:      strings.ToLower(v.Get("sort")),
+		Order:     strings.ToLower(v.Get("order")),
+		Page:      1,
+		Limit:     defaultPageLimit,
+		Algorithm: strings.ToLower(v.Get("algorithm")),
+		View:      strings.ToLower(v.Get("view")),
+	}
+
+	if q.View == "" {
+		q.View = "list"
+	}
+	if q.View != "list" && q.View != "matrix" {
+		return q, fmt.Errorf("invalid view %q", v.Get("view"))
+	}
+
+	if q.Algorithm == "" {
+		q.Algorithm = AlgorithmWCAG
+	}
+	if !validAlgorithm(q.Algorithm) {
+		return q, fmt.Errorf("invalid algorithm %q", v.Get("algorithm"))
+	}
+
+	if q.Search == "" {
+		q.Search = v.Get("search")
+	}
+	expr, err := ParseFilter(q.Search)
+	if err != nil {
+		return q, err
+	}
+	q.Expr = expr
+
+	if q.Filter != "" {
+		category, err := ParseCategory(q.Filter)
+		if err != nil {
+			return q, fmt.Errorf("invalid filter %q", v.Get("filter"))
+		}
+		q.FilterCategory = category
+	}
+
+	if q.Sort == "" {
+		q.Sort = "foreground"
+	}
+	if _, ok := sortFields[q.Sort]; !ok {
+		return q, fmt.Errorf("invalid sort %q", v.Get("sort"))
+	}
+
+	if q.Order == "" {
+		q.Order = "asc"
+	}
+	if q.Order != "asc" && q.Order != "desc" {
+		return q, fmt.Errorf("invalid order %q", v.Get("order"))
+	}
+
+	if s := v.Get("page"); s != "" {
+		page, err := strconv.Atoi(s)
+		if err != nil || page < 1 {
+			return q, fmt.Errorf("invalid page %q", s)
+		}
+		q.Page = page
+	}
+
+	if s := v.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return q, fmt.Errorf("invalid limit %q (must be 1-%d)", s, maxPageLimit)
+		}
+		q.Limit = limit
+	}
+
+	return q, nil
+}
+
+// bucketResults groups results by category, keeping their order.
+func bucketResults(results []ContrastResult) WCAGLevels {
+	levels := WCAGLevels{
+		AAA:     []ContrastResult{},
+		AA:      []ContrastResult{},
+		AALarge: []ContrastResult{},
+		Fail:    []ContrastResult{},
+	}
+
+	for _, result := range results {
+		switch result.Category {
+		case CategoryAAA:
+			levels.AAA = append(levels.AAA, result)
+		case CategoryAA:
+			levels.AA = append(levels.AA, result)
+		case CategoryAALarge:
+			levels.AALarge = append(levels.AALarge, result)
+		default:
+			levels.Fail = append(levels.Fail, result)
+		}
+	}
+	return levels
+}
+
+// queryResults filters, sorts, and pages the palette's contrast results.
+func queryResults(ctx context.Context, colors *ColorSets, q ResultQuery) ResultPage {
+	return paginateResults(matchResults(ctx, colors, q), q.Page, q.Limit)
+}
+
+// allResults is queryResults without paging: Results holds every match,
+// as one page whose limit is the number of matches.
+func allResults(ctx context.Context, colors *ColorSets, q ResultQuery) ResultPage {
+	page := matchResults(ctx, colors, q)
+	page.Page, page.Limit, page.Pages = 1, page.Total, min(page.Total, 1)
+	return page
+}
+
+// matchResults filters and sorts the palette's contrast results, returning
+// every match with the totals. Page, Limit, and Pages are left to the
+// caller.
+func matchResults(ctx context.Context, colors *ColorSets, q ResultQuery) ResultPage {
+	all, warnings := contrastResults(ctx, colors)
+
+	filtered := make([]ContrastResult, 0, len(all))
+	for _, result := range all {
+		if q.Expr.Match(result) && (q.Filter == "" || result.Category == q.FilterCategory) {
+			filtered = append(filtered, result)
+		}
+	}
+
+	compare := sortFields[q.Sort]
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if q.Order == "desc" {
+			return compare(filtered[i], filtered[j]) > 0
+		}
+		return compare(filtered[i], filtered[j]) < 0
+	})
+
+	levels := bucketResults(filtered)
+	page := ResultPage{
+		Results: filtered,
+		Totals: LevelCounts{
+			AAA:     len(levels.AAA),
+			AA:      len(levels.AA),
+			AALarge: len(levels.AALarge),
+			Fail:    len(levels.Fail),
+		},
+		Total:         len(filtered),
+		Sort:          q.Sort,
+		Order:         q.Order,
+		SRGBThreshold: srgbThreshold,
+		Warnings:      warnings,
+	}
+	if page.Warnings == nil {
+		page.Warnings = []PaletteWarning{}
+	}
+	return page
+}
+
+// paginateResults cuts the matches of matchResults down to one page.
+func paginateResults(page ResultPage, number, limit int) ResultPage {
+	page.Page, page.Limit = number, limit
+	page.Pages = (page.Total + limit - 1) / limit
+
+	start := (number - 1) * limit
+	if start > page.Total {
+		start = page.Total
+	}
+	end := start + limit
+	if end > page.Total {
+		end = page.Total
+	}
+	page.Results = page.Results[start:end]
+	return page
+}
+
+// summarizeLevels counts every contrast result of colors by category.
+func summarizeLevels(ctx context.Context, colors *ColorSets) LevelCounts {
+	results, _ := contrastResults(ctx, colors)
+	levels := bucketResults(results)
+	return LevelCounts{
+		AAA:     len(levels.AAA),
+		AA:      len(levels.AA),
+		AALarge: len(levels.AALarge),
+		Fail:    len(levels.Fail),
+	}
+}
+
+// pageURL returns u with its page parameter set to page.
+func pageURL(u *url.URL, page int) string {
+	v := u.Query()
+	v.Set("page", strconv.Itoa(page))
+	return u.Path + "?" + v.Encode()
+}
@@ -0,0 +1,770 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ScanFinding is the contrast of one text node found by scanning a page.
+type ScanFinding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Selector locates the text's element, from the nearest ancestor with
+	// an id, or from body.
+	Selector         string  `json:"selector"`
+	Text             string  `json:"text"`
+	ForegroundHex    string  `json:"foregroundHex"`
+	BackgroundHex    string  `json:"backgroundHex"`
+	ColorSource      string  `json:"colorSource"`
+	BackgroundSource string  `json:"backgroundSource"`
+	FontSizePx       float64 `json:"fontSizePx"`
+	FontWeight       int     `json:"fontWeight"`
+	Target           Target  `json:"target"`
+	ContrastRatio    float64 `json:"contrastRatio"`
+	ContrastRatioRaw float64 `json:"contrastRatioRaw"`
+	Level            Level   `json:"level"`
+	Pass             bool    `json:"pass"`
+}
+
+// htmlElement is an element of a scanned page.
+type htmlElement struct {
+	Tag      string
+	Attrs    map[string]string
+	Line     int
+	Parent   *htmlElement
+	Children []*htmlElement
+	Texts    []htmlText
+	index    int
+}
+
+type htmlText struct {
+	Text string
+	Line int
+}
+
+func (e *htmlElement) hasClass(class string) bool {
+	for _, c := range strings.Fields(e.Attrs["class"]) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *htmlElement) previousSibling() *htmlElement {
+	if e.Parent == nil || e.index == 0 {
+		return nil
+	}
+	return e.Parent.Children[e.index-1]
+}
+
+func (e *htmlElement) appendChild(c *htmlElement) {
+	c.Parent = e
+	c.index = len(e.Children)
+	e.Children = append(e.Children, c)
+}
+
+// selectorPath describes e as a selector such as "#main > p.note".
+func (e *htmlElement) selectorPath() string {
+	var parts []string
+	for p := e; p != nil; p = p.Parent {
+		part := p.Tag
+		if id := p.Attrs["id"]; id != "" {
+			parts = append(parts, "#"+id)
+			break
+		}
+		for _, c := range strings.Fields(p.Attrs["class"]) {
+			part += "." + c
+		}
+		parts = append(parts, part)
+		if p.Tag == "body" {
+			break
+		}
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// closesParagraph lists the elements whose start tag ends an open p.
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "div": true,
+	"dl": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// impliedEnds lists, for a start tag, the open elements it implicitly ends.
+var impliedEnds = map[string][]string{
+	"li":     {"li"},
+	"dt":     {"dt", "dd"},
+	"dd":     {"dt", "dd"},
+	"tr":     {"td", "th", "tr"},
+	"td":     {"td", "th"},
+	"th":     {"td", "th"},
+	"option": {"option"},
+}
+
+// htmlStyleSource is a style element or linked stylesheet, in document order.
+type htmlStyleSource struct {
+	// Text and Line are set for a style element, Href for a link.
+	Text string
+	Line int
+	Href string
+}
+
+// parseHTMLTree builds an element tree from r. It handles void elements and
+// the common implied end tags, which is as much of the HTML parsing
+// algorithm as locating text needs, and keeps the line of every element and
+// text node.
+func parseHTMLTree(r io.Reader) (*htmlElement, []htmlStyleSource, error) {
+	doc := &htmlElement{}
+	stack := []*htmlElement{doc}
+	var styles []htmlStyleSource
+	inStyle := false
+	line := 1
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				break
+			}
+			return nil, nil, z.Err()
+		}
+		start := line
+		line += strings.Count(string(z.Raw()), "\n")
+		top := stack[len(stack)-1]
+
+		switch tt {
+		case html.TextToken:
+			text := string(z.Text())
+			if inStyle {
+				styles = append(styles, htmlStyleSource{Text: text, Line: start})
+				continue
+			}
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" {
+				continue
+			}
+			lead := text[:strings.Index(text, trimmed[:1])]
+			top.Texts = append(top.Texts, htmlText{Text: trimmed, Line: start + strings.Count(lead, "\n")})
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			e := &htmlElement{Tag: tok.Data, Attrs: map[string]string{}, Line: start}
+			for _, a := range tok.Attr {
+				e.Attrs[a.Key] = a.Val
+			}
+			if closesParagraph[e.Tag] && top.Tag == "p" {
+				stack = stack[:len(stack)-1]
+			}
+			for _, end := range impliedEnds[e.Tag] {
+				if stack[len(stack)-1].Tag == end {
+					stack = stack[:len(stack)-1]
+				}
+			}
+			stack[len(stack)-1].appendChild(e)
+			if e.Tag == "link" && isStylesheetLink(e) {
+				styles = append(styles, htmlStyleSource{Href: e.Attrs["href"], Line: start})
+			}
+			if tt == html.StartTagToken && !voidElements[e.Tag] {
+				stack = append(stack, e)
+				inStyle = e.Tag == "style"
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].Tag == string(name) {
+					stack = stack[:i]
+					break
+				}
+			}
+			inStyle = false
+		}
+	}
+
+	// Use the html element as the root, adding one if the page has none.
+	if len(doc.Children) == 1 && doc.Children[0].Tag == "html" {
+		root := doc.Children[0]
+		root.Parent = nil
+		return root, styles, nil
+	}
+	root := &htmlElement{Tag: "html", Attrs: map[string]string{}, Line: 1, Texts: doc.Texts}
+	for _, c := range doc.Children {
+		root.appendChild(c)
+	}
+	return root, styles, nil
+}
+
+func isStylesheetLink(e *htmlElement) bool {
+	for _, rel := range strings.Fields(strings.ToLower(e.Attrs["rel"])) {
+		if rel == "stylesheet" {
+			return true
+		}
+	}
+	return false
+}
+
+// uaStyleSheet is the part of the browser default stylesheet that affects
+// which text is shown and how large and heavy it is.
+const uaStyleSheet = `
+head, script, style, title, noscript, template, [hidden] { display: none }
+h1 { font-size: 2em; font-weight: bold }
+h2 { font-size: 1.5em; font-weight: bold }
+h3 { font-size: 1.17em; font-weight: bold }
+h4 { font-weight: bold }
+h5 { font-size: 0.83em; font-weight: bold }
+h6 { font-size: 0.67em; font-weight: bold }
+b, strong, th { font-weight: bold }
+small { font-size: smaller }
+a[href] { color: #0000ee }
+`
+
+var uaRules = parseCSS(uaStyleSheet, "default", 1)
+
+// computedStyle is the part of an element's computed style that decides the
+// contrast of its text.
+type computedStyle struct {
+	Color       cssColor
+	ColorSource string
+	// Background is the opaque color behind the element's content, and
+	// BackgroundSource where its topmost layer was declared.
+	Background       cssColor
+	BackgroundSource string
+	// BackgroundImage is set when an image or gradient lies behind the
+	// content, so Background is not what is actually seen.
+	BackgroundImage bool
+	FontSizePx      float64
+	FontWeight      int
+	// Hidden is set by visibility: hidden, which children can undo.
+	Hidden bool
+	Vars   map[string]string
+}
+
+var rootStyle = computedStyle{
+	Color:            cssBlack,
+	ColorSource:      "default",
+	Background:       cssWhite,
+	BackgroundSource: "default",
+	FontSizePx:       remPx,
+	FontWeight:       400,
+	Vars:             map[string]string{},
+}
+
+// cascadeDecl is a declaration that applies to an element, with what the
+// cascade needs to order it.
+type cascadeDecl struct {
+	cssDecl
+	rank        int
+	specificity [3]int
+	order       int
+}
+
+// Cascade ranks, lowest first.
+const (
+	rankDefault = iota
+	rankAuthor
+	rankInline
+	rankAuthorImportant
+	rankInlineImportant
+)
+
+// shorthandOf lists the shorthands that also set a property.
+var shorthandOf = map[string]string{
+	"background-color": "background",
+	"background-image": "background",
+	"font-size":        "font",
+	"font-weight":      "font",
+}
+
+// cascade returns the winning declaration of every property set on e.
+func cascade(e *htmlElement, rules []cssRule, file string) map[string]cssDecl {
+	decls := matchedDecls(e, uaRules, rankDefault)
+	decls = append(decls, matchedDecls(e, rules, rankAuthor)...)
+	decls = append(decls, inlineDecls(e, file)...)
+	return winningDecls(decls)
+}
+
+// matchedDecls returns the declarations of the rules that match e, ranked
+// by origin.
+func matchedDecls(e *htmlElement, rules []cssRule, rank int) []cascadeDecl {
+	var decls []cascadeDecl
+	for _, rule := range rules {
+		best, matched := [3]int{}, false
+		for _, s := range rule.Selectors {
+			if s.matches(e) && (!matched || compareSpecificity(s.Specificity, best) > 0) {
+				best, matched = s.Specificity, true
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, d := range rule.Decls {
+			r := rank
+			if d.Important && rank == rankAuthor {
+				r = rankAuthorImportant
+			}
+			decls = append(decls, cascadeDecl{cssDecl: d, rank: r, specificity: best, order: rule.Order})
+		}
+	}
+	return decls
+}
+
+// inlineDecls returns the declarations of e's style attribute.
+func inlineDecls(e *htmlElement, file string) []cascadeDecl {
+	var decls []cascadeDecl
+	if style, ok := e.Attrs["style"]; ok {
+		for _, d := range parseDecls(style, fmt.Sprintf("%s:%d (inline)", file, e.Line)) {
+			r := rankInline
+			if d.Important {
+				r = rankInlineImportant
+			}
+			decls = append(decls, cascadeDecl{cssDecl: d, rank: r})
+		}
+	}
+	return decls
+}
+
+// winningDecls sorts decls into cascade order and returns the last
+// declaration of every property.
+func winningDecls(decls []cascadeDecl) map[string]cssDecl {
+	sort.SliceStable(decls, func(i, j int) bool {
+		a, b := decls[i], decls[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if c := compareSpecificity(a.specificity, b.specificity); c != 0 {
+			return c < 0
+		}
+		return a.order < b.order
+	})
+	won := map[string]cssDecl{}
+	for _, d := range decls {
+		won[d.Prop] = d.cssDecl
+		for longhand, shorthand := range shorthandOf {
+			if d.Prop == shorthand {
+				won[longhand] = d.cssDecl
+			}
+		}
+	}
+	return won
+}
+
+// substituteVars replaces var() references in value. ok is false when a
+// reference has neither a value nor a fallback, or refers back to itself.
+func substituteVars(value string, vars map[string]string, depth int) (string, bool) {
+	if depth > 16 {
+		return "", false
+	}
+	for {
+		i := strings.Index(value, "var(")
+		if i < 0 {
+			return value, true
+		}
+		end, level := -1, 0
+		for j := i + 3; j < len(value); j++ {
+			if value[j] == '(' {
+				level++
+			} else if value[j] == ')' {
+				level--
+				if level == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return "", false
+		}
+		name, fallback, hasFallback := strings.Cut(value[i+4:end], ",")
+		repl, ok := vars[strings.TrimSpace(name)]
+		if !ok {
+			if !hasFallback {
+				return "", false
+			}
+			repl = fallback
+		}
+		repl, ok = substituteVars(strings.TrimSpace(repl), vars, depth+1)
+		if !ok {
+			return "", false
+		}
+		value = value[:i] + repl + value[end+1:]
+	}
+}
+
+// splitCSSValue splits a value at top-level spaces, ignoring those inside
+// functions.
+func splitCSSValue(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || depth == 0 && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+			if p := strings.TrimSpace(s[start:i]); p != "" {
+				parts = append(parts, p)
+			}
+			start = i + 1
+			continue
+		}
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return parts
+}
+
+// backgroundLayers reads the color and whether there is an image from a
+// background shorthand. The color is only allowed in the last layer.
+func backgroundLayers(value string) (color string, image bool) {
+	color = "transparent"
+	lower := strings.ToLower(value)
+	image = strings.Contains(lower, "url(") || strings.Contains(lower, "gradient(")
+	depth, last := 0, 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				last = i + 1
+			}
+		}
+	}
+	for _, part := range splitCSSValue(value[last:]) {
+		if _, ok := parseCSSColor(part); ok || strings.EqualFold(part, "currentcolor") {
+			color = part
+		}
+	}
+	return color, image
+}
+
+var fontSizeKeywords = map[string]float64{
+	"xx-small": 9, "x-small": 10, "small": 13, "medium": 16,
+	"large": 18, "x-large": 24, "xx-large": 32, "xxx-large": 48,
+}
+
+// fontSizePx resolves a font-size value against the parent's size.
+func fontSizePx(value string, parent float64) (float64, bool) {
+	v := strings.ToLower(value)
+	if px, ok := fontSizeKeywords[v]; ok {
+		return px, true
+	}
+	switch {
+	case v == "larger":
+		return parent * 1.2, true
+	case v == "smaller":
+		return parent / 1.2, true
+	case strings.HasSuffix(v, "rem"):
+	case strings.HasSuffix(v, "em"):
+		n, err := strconv.ParseFloat(strings.TrimSuffix(v, "em"), 64)
+		return n * parent, err == nil && n > 0
+	case strings.HasSuffix(v, "%"):
+		n, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		return n / 100 * parent, err == nil && n > 0
+	}
+	px, err := parseFontSize(v)
+	return px, err == nil
+}
+
+// fontWeight resolves a font-weight value against the parent's weight.
+func fontWeight(value string, parent int) (int, bool) {
+	switch strings.ToLower(value) {
+	case "normal":
+		return 400, true
+	case "bold":
+		return 700, true
+	case "bolder":
+		switch {
+		case parent < 350:
+			return 400, true
+		case parent < 550:
+			return 700, true
+		}
+		return 900, true
+	case "lighter":
+		switch {
+		case parent < 100:
+			return parent, true
+		case parent < 550:
+			return 100, true
+		case parent < 750:
+			return 400, true
+		}
+		return 700, true
+	}
+	n, err := strconv.Atoi(value)
+	return n, err == nil && n >= 1 && n <= 1000
+}
+
+// fontShorthand reads the size and weight from a font shorthand such as
+// "bold 1.25rem/1.5 sans-serif".
+func fontShorthand(value string, parent computedStyle) (size float64, weight int) {
+	size, weight = remPx, 400
+	for _, part := range splitCSSValue(value) {
+		part, _, _ = strings.Cut(part, "/")
+		if w, ok := fontWeight(part, parent.FontWeight); ok && part != "normal" {
+			weight = w
+		} else if px, ok := fontSizePx(part, parent.FontSizePx); ok {
+			size = px
+			break
+		}
+	}
+	return size, weight
+}
+
+// computeStyle works out the style of an element from the declarations that
+// won the cascade and the style of its parent. displayed is false for
+// display: none, which hides the whole subtree.
+func computeStyle(decls map[string]cssDecl, parent computedStyle) (s computedStyle, displayed bool) {
+	s = parent
+	copied := false
+	for prop, d := range decls {
+		if !strings.HasPrefix(prop, "--") {
+			continue
+		}
+		if !copied {
+			s.Vars = make(map[string]string, len(parent.Vars)+1)
+			for k, v := range parent.Vars {
+				s.Vars[k] = v
+			}
+			copied = true
+		}
+		s.Vars[prop] = d.Value
+	}
+
+	// value returns the resolved value of prop, or "" when it is not set,
+	// inherits, or cannot be resolved.
+	value := func(prop string) (string, cssDecl) {
+		d, ok := decls[prop]
+		if !ok {
+			return "", d
+		}
+		v, ok := substituteVars(d.Value, s.Vars, 0)
+		if !ok {
+			return "", d
+		}
+		if d.Prop != prop {
+			switch d.Prop {
+			case "background":
+				color, image := backgroundLayers(v)
+				if prop == "background-image" {
+					if image {
+						return "url()", d
+					}
+					return "none", d
+				}
+				return color, d
+			case "font":
+				size, weight := fontShorthand(v, parent)
+				if prop == "font-size" {
+					return strconv.FormatFloat(size, 'f', -1, 64) + "px", d
+				}
+				return strconv.Itoa(weight), d
+			}
+		}
+		v = strings.TrimSpace(v)
+		switch strings.ToLower(v) {
+		case "inherit", "unset":
+			return "", d
+		}
+		return v, d
+	}
+
+	if v, _ := value("display"); strings.EqualFold(v, "none") {
+		return s, false
+	}
+	if v, _ := value("visibility"); v != "" {
+		s.Hidden = strings.EqualFold(v, "hidden") || strings.EqualFold(v, "collapse")
+	}
+	if v, _ := value("font-size"); v != "" {
+		if px, ok := fontSizePx(v, parent.FontSizePx); ok {
+			s.FontSizePx = px
+		}
+	}
+	if v, _ := value("font-weight"); v != "" {
+		if w, ok := fontWeight(v, parent.FontWeight); ok {
+			s.FontWeight = w
+		}
+	}
+	if v, d := value("color"); v != "" {
+		switch strings.ToLower(v) {
+		case "initial":
+			s.Color, s.ColorSource = cssBlack, d.Source
+		case "currentcolor":
+		default:
+			if c, ok := parseCSSColor(v); ok {
+				s.Color, s.ColorSource = c, d.Source
+			}
+		}
+	}
+	if v, d := value("background-color"); v != "" {
+		c, ok := parseCSSColor(v)
+		switch strings.ToLower(v) {
+		case "initial":
+			c, ok = cssColor{}, true
+		case "currentcolor":
+			c, ok = s.Color, true
+		}
+		if ok && c.A > 0 {
+			s.Background, s.BackgroundSource = c.over(parent.Background), d.Source
+			if c.A == 1 {
+				s.BackgroundImage = false
+			}
+		}
+	}
+	if v, d := value("background-image"); v != "" && !strings.EqualFold(v, "none") && !strings.EqualFold(v, "initial") {
+		s.BackgroundImage, s.BackgroundSource = true, d.Source
+	}
+	return s, true
+}
+
+// noTextElements hold text that is never rendered as page text.
+var noTextElements = map[string]bool{
+	"script": true, "style": true, "head": true, "title": true, "noscript": true, "template": true, "textarea": true,
+}
+
+// scanPage computes the style of every element of root and reports the
+// contrast of each visible text node. Text in front of a background image
+// cannot be judged and is reported as a warning instead.
+func scanPage(root *htmlElement, rules []cssRule, file string) ([]ScanFinding, []string) {
+	var findings []ScanFinding
+	var warnings []string
+	var walk func(e *htmlElement, parent computedStyle)
+	walk = func(e *htmlElement, parent computedStyle) {
+		s, displayed := computeStyle(cascade(e, rules, file), parent)
+		if !displayed || noTextElements[e.Tag] {
+			return
+		}
+		if !s.Hidden {
+			for _, t := range e.Texts {
+				if s.BackgroundImage {
+					warnings = append(warnings, fmt.Sprintf("%s:%d: %s: text over a background image (%s) was not checked",
+						file, t.Line, e.selectorPath(), s.BackgroundSource))
+					continue
+				}
+				findings = append(findings, newScanFinding(file, e, t, s))
+			}
+		}
+		for _, c := range e.Children {
+			walk(c, s)
+		}
+	}
+	walk(root, rootStyle)
+	return findings, warnings
+}
+
+func newScanFinding(file string, e *htmlElement, t htmlText, s computedStyle) ScanFinding {
+	fg := s.Color.over(s.Background).hex()
+	bg := s.Background.hex()
+	ratio, _ := contrastRatio(fg, bg)
+	text := t.Text
+	if r := []rune(text); len(r) > 40 {
+		text = string(r[:39]) + "…"
+	}
+	target := textTarget(s.FontSizePx, s.FontWeight)
+	level := levelFor(target, ratio)
+	return ScanFinding{
+		File:             file,
+		Line:             t.Line,
+		Selector:         e.selectorPath(),
+		Text:             strings.Join(strings.Fields(text), " "),
+		ForegroundHex:    fg,
+		BackgroundHex:    bg,
+		ColorSource:      s.ColorSource,
+		BackgroundSource: s.BackgroundSource,
+		FontSizePx:       s.FontSizePx,
+		FontWeight:       s.FontWeight,
+		Target:           target,
+		ContrastRatio:    DisplayRatio(ratio),
+		ContrastRatioRaw: ratio,
+		Level:            level,
+		Pass:             level >= LevelAA,
+	}
+}
+
+// stylesheetCache parses each stylesheet file once per scan.
+type stylesheetCache map[string][]cssRule
+
+func (c stylesheetCache) load(path string) ([]cssRule, error) {
+	if rules, ok := c[path]; ok {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := parseCSS(string(data), path, 1)
+	c[path] = rules
+	return rules, nil
+}
+
+// scanHTMLFile scans one page. extra are stylesheets applied before the
+// page's own, such as a site-wide sheet the page does not link itself.
+func scanHTMLFile(path string, extra []string, cache stylesheetCache) ([]ScanFinding, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	root, sources, err := parseHTMLTree(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var warnings []string
+	var rules []cssRule
+	addRules := func(rs []cssRule) {
+		for _, r := range rs {
+			r.Order = len(rules)
+			rules = append(rules, r)
+		}
+	}
+	for _, sheet := range extra {
+		rs, err := cache.load(sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+		addRules(rs)
+	}
+	for _, src := range sources {
+		if src.Href == "" {
+			addRules(parseCSS(src.Text, path, src.Line))
+			continue
+		}
+		if strings.Contains(src.Href, "://") || strings.HasPrefix(src.Href, "//") {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: remote stylesheet %s was not loaded", path, src.Line, src.Href))
+			continue
+		}
+		href, _, _ := strings.Cut(src.Href, "?")
+		sheet := filepath.Join(filepath.Dir(path), filepath.FromSlash(href))
+		if strings.HasPrefix(href, "/") {
+			sheet = filepath.FromSlash(strings.TrimPrefix(href, "/"))
+		}
+		rs, err := cache.load(sheet)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: %v", path, src.Line, err))
+			continue
+		}
+		addRules(rs)
+	}
+
+	findings, pageWarnings := scanPage(root, rules, path)
+	return findings, append(warnings, pageWarnings...), nil
+}
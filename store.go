@@ -0,0 +1,661 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store keeps editable palettes in SQLite. Every palette carries a version
+// that increases with each change, for optimistic concurrency.
+type Store struct {
+	db *sql.DB
+	// wake tells the webhook sender that deliveries were queued.
+	wake chan struct{}
+}
+
+// store is the palette store, or nil when the server reads colors.json.
+var store *Store
+
+// defaultProject holds the palettes served outside /projects/, and
+// defaultPalette is the palette used when a request or project names none.
+const (
+	defaultProject = "default"
+	defaultPalette = "default"
+)
+
+// Expected versions for Store.Update besides an exact version.
+const (
+	// mustCreate fails with errPaletteExists if the palette exists.
+	mustCreate = 0
+	// anyVersion accepts whatever version the palette is at.
+	anyVersion = -1
+)
+
+var (
+	errNotFound        = errors.New("not found")
+	errPaletteExists   = errors.New("palette already exists")
+	errVersionMismatch = errors.New("palette has been changed since it was read")
+	errInvalidPalette  = errors.New("invalid palette")
+	errProjectExists   = errors.New("project already exists")
+	errInvalidProject  = errors.New("invalid project")
+	errInvalidWebhook  = errors.New("invalid webhook")
+)
+
+// Change describes who made a change to a palette and why.
+type Change struct {
+	Author  string
+	Message string
+}
+
+// PaletteVersion is one entry of a palette's history. Deleting a palette
+// is recorded as a version without colors.
+type PaletteVersion struct {
+	Project   string    `json:"project"`
+	Palette   string    `json:"palette"`
+	Version   int       `json:"version"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	// Summary counts the palette's results in each category at this
+	// version.
+	Summary LevelCounts `json:"summary"`
+	// Colors is left out of version lists.
+	Colors *ColorSets `json:"colors,omitempty"`
+}
+
+// StoredPalette is a palette with its version.
+type StoredPalette struct {
+	Project   string    `json:"project"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ColorSets
+}
+
+// storeSchema is the current schema, numbered by storeSchemaVersion in the
+// database's user_version.
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	settings   TEXT NOT NULL DEFAULT '{}',
+	created_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS palettes (
+	project    TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+	name       TEXT NOT NULL,
+	version    INTEGER NOT NULL,
+	usages     TEXT NOT NULL DEFAULT '[]',
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (project, name)
+);
+CREATE TABLE IF NOT EXISTS colors (
+	project TEXT NOT NULL,
+	palette TEXT NOT NULL,
+	theme   TEXT NOT NULL,
+	name    TEXT NOT NULL,
+	hex     TEXT NOT NULL,
+	PRIMARY KEY (project, palette, theme, name),
+	FOREIGN KEY (project, palette) REFERENCES palettes(project, name) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS versions (
+	project    TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+	palette    TEXT NOT NULL,
+	version    INTEGER NOT NULL,
+	author     TEXT NOT NULL,
+	message    TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	deleted    INTEGER NOT NULL DEFAULT 0,
+	summary    TEXT NOT NULL,
+	colors     TEXT NOT NULL,
+	PRIMARY KEY (project, palette, version)
+);
+CREATE TABLE IF NOT EXISTS webhooks (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	project          TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+	url              TEXT NOT NULL,
+	secret           TEXT NOT NULL,
+	palettes         TEXT NOT NULL DEFAULT '[]',
+	regressions_only INTEGER NOT NULL DEFAULT 0,
+	disabled         INTEGER NOT NULL DEFAULT 0,
+	created_at       TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS deliveries (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook      INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	event        TEXT NOT NULL,
+	palette      TEXT NOT NULL,
+	version      INTEGER NOT NULL,
+	payload      TEXT NOT NULL,
+	state        TEXT NOT NULL,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	status       INTEGER NOT NULL DEFAULT 0,
+	error        TEXT NOT NULL DEFAULT '',
+	next_attempt INTEGER NOT NULL,
+	created_at   TEXT NOT NULL,
+	updated_at   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS deliveries_pending ON deliveries (state, next_attempt);
+`
+
+// storeSchemaVersion 2 added webhooks. Its tables are created by
+// storeSchema like the others.
+const storeSchemaVersion = 2
+
+// migrateUnversioned moves the tables of a database from before projects,
+// when user_version was still 0, into the default project.
+const migrateUnversioned = `
+CREATE TABLE IF NOT EXISTS versions (
+	palette TEXT, version INTEGER, author TEXT, message TEXT, created_at TEXT,
+	deleted INTEGER, summary TEXT, colors TEXT
+);
+ALTER TABLE colors RENAME TO colors_v0;
+ALTER TABLE palettes RENAME TO palettes_v0;
+ALTER TABLE versions RENAME TO versions_v0;
+` + storeSchema + `
+INSERT INTO projects (id, name, created_at) VALUES ('default', 'Default', strftime('%Y-%m-%dT%H:%M:%fZ'));
+INSERT INTO palettes SELECT 'default', name, version, usages, updated_at FROM palettes_v0;
+INSERT INTO colors SELECT 'default', palette, theme, name, hex FROM colors_v0;
+INSERT INTO versions SELECT 'default', palette, version, author, message, created_at, deleted, summary, colors FROM versions_v0;
+DROP TABLE colors_v0;
+DROP TABLE palettes_v0;
+DROP TABLE versions_v0;
+`
+
+// openStore opens or creates the SQLite database at path.
+func openStore(path string) (*Store, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Store{db: db, wake: make(chan struct{}, 1)}, nil
+}
+
+// migrateStore brings the schema up to date and makes sure the default
+// project exists.
+func migrateStore(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version, tables int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'palettes'").Scan(&tables); err != nil {
+		return err
+	}
+	switch {
+	case version > storeSchemaVersion:
+		return fmt.Errorf("database schema %d is newer than this server (%d)", version, storeSchemaVersion)
+	case version == 0 && tables > 0:
+		_, err = tx.Exec(migrateUnversioned)
+	default:
+		_, err = tx.Exec(storeSchema)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR IGNORE INTO projects (id, name, created_at) VALUES (?, 'Default', ?)`,
+		defaultProject, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", storeSchemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Seed stores colors as the named palette of the default project if the
+// store holds no palettes yet, so a new database starts from colors.json.
+// Invalid colors are left out, as they are from the results.
+func (s *Store) Seed(ctx context.Context, name string, colors *ColorSets) error {
+	var n int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM palettes").Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	seed := Change{Author: "colors.json", Message: "Seed from colors.json"}
+	_, _, err := s.Update(ctx, defaultProject, name, mustCreate, seed, func(cs *ColorSets) error {
+		cs.Usages = colors.Usages
+		for _, theme := range []string{"light", "dark"} {
+			valid, _ := validColorNames(ctx, theme, colors.theme(theme))
+			for _, color := range valid {
+				if validName(color) != nil {
+					continue
+				}
+				cs.theme(theme)[color] = colors.theme(theme)[color]
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// List returns every palette of a project, by name.
+func (s *Store) List(ctx context.Context, project string) ([]StoredPalette, error) {
+	if _, err := s.Project(ctx, project); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM palettes WHERE project = ? ORDER BY name", project)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	palettes := []StoredPalette{}
+	for _, name := range names {
+		p, err := s.Get(ctx, project, name)
+		if err != nil {
+			return nil, err
+		}
+		palettes = append(palettes, p)
+	}
+	return palettes, nil
+}
+
+// querier is what reading a palette needs from a *sql.DB or *sql.Tx.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func getPalette(ctx context.Context, q querier, project, name string) (StoredPalette, error) {
+	p := StoredPalette{Project: project, Name: name}
+	var usages, updated string
+	err := q.QueryRowContext(ctx, "SELECT version, usages, updated_at FROM palettes WHERE project = ? AND name = ?", project, name).
+		Scan(&p.Version, &usages, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, fmt.Errorf("palette %q: %w", name, errNotFound)
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(usages), &p.Usages); err != nil {
+		return p, fmt.Errorf("palette %q usages: %w", name, err)
+	}
+	if p.UpdatedAt, err = time.Parse(time.RFC3339Nano, updated); err != nil {
+		return p, err
+	}
+
+	p.Light, p.Dark = map[string]string{}, map[string]string{}
+	rows, err := q.QueryContext(ctx, "SELECT theme, name, hex FROM colors WHERE project = ? AND palette = ?", project, name)
+	if err != nil {
+		return p, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var theme, color, hex string
+		if err := rows.Scan(&theme, &color, &hex); err != nil {
+			return p, err
+		}
+		if m := p.theme(theme); m != nil {
+			m[color] = hex
+		}
+	}
+	return p, rows.Err()
+}
+
+// Get returns the named palette of a project, or errNotFound.
+func (s *Store) Get(ctx context.Context, project, name string) (StoredPalette, error) {
+	return getPalette(ctx, s.db, project, name)
+}
+
+// theme returns the colors of the light or dark theme, or nil for any
+// other name.
+func (cs *ColorSets) theme(name string) map[string]string {
+	switch name {
+	case "light":
+		return cs.Light
+	case "dark":
+		return cs.Dark
+	}
+	return nil
+}
+
+// Update applies edit to the named palette of a project and saves the result as the
+// next version, recording change in its history. expected is the version
+// the caller last read, mustCreate to create a new palette, or anyVersion.
+// A palette that does not exist starts out empty, numbered after any
+// earlier palette of the same name, and the edited palette must pass
+// validatePalette.
+func (s *Store) Update(ctx context.Context, project, name string, expected int, change Change, edit func(*ColorSets) error) (StoredPalette, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return StoredPalette{}, false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getProject(ctx, tx, project); err != nil {
+		return StoredPalette{}, false, err
+	}
+	p, err := getPalette(ctx, tx, project, name)
+	created := errors.Is(err, errNotFound)
+	switch {
+	case created && expected != mustCreate:
+		return p, false, err
+	case created:
+		p.ColorSets = ColorSets{Light: map[string]string{}, Dark: map[string]string{}}
+		if p.Version, err = lastVersion(ctx, tx, project, name); err != nil {
+			return p, false, err
+		}
+	case err != nil:
+		return p, false, err
+	case expected == mustCreate:
+		return p, false, errPaletteExists
+	case expected != anyVersion && expected != p.Version:
+		return p, false, errVersionMismatch
+	}
+
+	if err := edit(&p.ColorSets); err != nil {
+		return p, false, err
+	}
+	if err := validatePalette(&p.ColorSets); err != nil {
+		return p, false, err
+	}
+	usages, err := json.Marshal(p.Usages)
+	if err != nil {
+		return p, false, err
+	}
+	if p.Usages == nil {
+		usages = []byte("[]")
+	}
+
+	p.Version++
+	p.UpdatedAt = time.Now().UTC()
+	_, err = tx.ExecContext(ctx, `INSERT INTO palettes (project, name, version, usages, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project, name) DO UPDATE SET version = excluded.version, usages = excluded.usages, updated_at = excluded.updated_at`,
+		project, name, p.Version, string(usages), p.UpdatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return p, false, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM colors WHERE project = ? AND palette = ?", project, name); err != nil {
+		return p, false, err
+	}
+	for _, theme := range []string{"light", "dark"} {
+		for color, hex := range p.theme(theme) {
+			_, err := tx.ExecContext(ctx, "INSERT INTO colors (project, palette, theme, name, hex) VALUES (?, ?, ?, ?, ?)", project, name, theme, color, hex)
+			if err != nil {
+				return p, false, err
+			}
+		}
+	}
+	v := PaletteVersion{Project: project, Palette: name, Version: p.Version, CreatedAt: p.UpdatedAt, Colors: &p.ColorSets}
+	if err := recordVersion(ctx, tx, v, change); err != nil {
+		return p, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return p, false, err
+	}
+	s.wakeWebhooks()
+	paletteChanges.publish(paletteChange{project, name})
+	return p, created, nil
+}
+
+// lastVersion returns the newest version in the history of a palette, or 0.
+func lastVersion(ctx context.Context, tx *sql.Tx, project, name string) (int, error) {
+	var version int
+	err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM versions WHERE project = ? AND palette = ?", project, name).Scan(&version)
+	return version, err
+}
+
+// recordVersion adds v to the history with its summary and queues the
+// project's webhooks.
+func recordVersion(ctx context.Context, tx *sql.Tx, v PaletteVersion, change Change) error {
+	v.Summary = summarizeLevels(ctx, v.Colors)
+	summary, err := json.Marshal(v.Summary)
+	if err != nil {
+		return err
+	}
+	colors, err := json.Marshal(v.Colors)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO versions (project, palette, version, author, message, created_at, deleted, summary, colors)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		v.Project, v.Palette, v.Version, change.Author, change.Message, v.CreatedAt.Format(time.RFC3339Nano), v.Deleted, string(summary), string(colors))
+	if err != nil {
+		return err
+	}
+	return queueWebhooks(ctx, tx, v, change)
+}
+
+// Delete removes the named palette of a project if it is still at the
+// expected version, recording the deletion as the next version.
+func (s *Store) Delete(ctx context.Context, project, name string, expected int, change Change) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p, err := getPalette(ctx, tx, project, name)
+	if err != nil {
+		return err
+	}
+	if expected != anyVersion && expected != p.Version {
+		return errVersionMismatch
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM palettes WHERE project = ? AND name = ?", project, name); err != nil {
+		return err
+	}
+	v := PaletteVersion{
+		Project:   project,
+		Palette:   name,
+		Version:   p.Version + 1,
+		CreatedAt: time.Now().UTC(),
+		Deleted:   true,
+		Colors:    &ColorSets{Light: map[string]string{}, Dark: map[string]string{}},
+	}
+	if err := recordVersion(ctx, tx, v, change); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.wakeWebhooks()
+	paletteChanges.publish(paletteChange{project, name})
+	return nil
+}
+
+const versionColumns = "project, palette, version, author, message, created_at, deleted, summary"
+
+func scanVersion(row interface{ Scan(...any) error }, colors *string) (PaletteVersion, error) {
+	var v PaletteVersion
+	var created, summary string
+	dest := []any{&v.Project, &v.Palette, &v.Version, &v.Author, &v.Message, &created, &v.Deleted, &summary}
+	if colors != nil {
+		dest = append(dest, colors)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return v, err
+	}
+	var err error
+	if v.CreatedAt, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return v, err
+	}
+	return v, json.Unmarshal([]byte(summary), &v.Summary)
+}
+
+// Versions returns the history of the named palette of a project, newest
+// first. The history outlives the palette, so it is only errNotFound when
+// the name was never used.
+func (s *Store) Versions(ctx context.Context, project, name string) ([]PaletteVersion, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+versionColumns+" FROM versions WHERE project = ? AND palette = ? ORDER BY version DESC", project, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := []PaletteVersion{}
+	for rows.Next() {
+		v, err := scanVersion(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("palette %q: %w", name, errNotFound)
+	}
+	return versions, nil
+}
+
+// Version returns one version of the named palette of a project with its
+// colors.
+func (s *Store) Version(ctx context.Context, project, name string, version int) (PaletteVersion, error) {
+	var colors string
+	row := s.db.QueryRowContext(ctx, "SELECT "+versionColumns+", colors FROM versions WHERE project = ? AND palette = ? AND version = ?", project, name, version)
+	v, err := scanVersion(row, &colors)
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, fmt.Errorf("palette %q version %d: %w", name, version, errNotFound)
+	}
+	if err != nil {
+		return v, err
+	}
+	v.Colors = &ColorSets{}
+	return v, json.Unmarshal([]byte(colors), v.Colors)
+}
+
+// validName checks a palette or color name, which appear in URL paths.
+func validName(name string) error {
+	if name == "" || len(name) > 64 || strings.ContainsAny(name, "/?#") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("%w: invalid name %q", errInvalidPalette, name)
+	}
+	return nil
+}
+
+// normalizeColor parses any opaque CSS color and returns it as #rrggbb.
+func normalizeColor(value string) (string, error) {
+	c, ok := parseCSSColor(value)
+	if !ok || c.A != 1 {
+		return "", fmt.Errorf("%w: %q is not an opaque color", errInvalidPalette, value)
+	}
+	return c.hex(), nil
+}
+
+// validatePalette checks every color name and value, writing the values
+// back as #rrggbb.
+func validatePalette(cs *ColorSets) error {
+	if cs.Light == nil {
+		cs.Light = map[string]string{}
+	}
+	if cs.Dark == nil {
+		cs.Dark = map[string]string{}
+	}
+	for _, m := range []map[string]string{cs.Light, cs.Dark} {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := validName(name); err != nil {
+				return err
+			}
+			hex, err := normalizeColor(m[name])
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			m[name] = hex
+		}
+	}
+	return nil
+}
+
+// requestPalette returns the palette a request is about: findPalette of its
+// project and its palette and version parameters.
+func requestPalette(r *http.Request) (StoredPalette, error) {
+	project, err := requestProject(r)
+	if err != nil {
+		return StoredPalette{}, err
+	}
+	q := r.URL.Query()
+	return findPalette(r.Context(), project, q.Get("palette"), q.Get("version"))
+}
+
+// findPalette returns the stored palette of a project with the given name,
+// or else the one named by the project's settings, at the given version or
+// the current one. Without a store it is colors.json, unnamed and
+// unversioned, and there are no projects.
+func findPalette(ctx context.Context, project Project, name, version string) (StoredPalette, error) {
+	if store == nil {
+		colors, err := LoadColors("colors.json")
+		if err != nil {
+			return StoredPalette{}, err
+		}
+		return StoredPalette{ColorSets: *colors}, nil
+	}
+	if name == "" {
+		name = project.palette()
+	}
+	if version == "" {
+		return store.Get(ctx, project.ID, name)
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return StoredPalette{}, fmt.Errorf("palette %q version %q: %w", name, version, errNotFound)
+	}
+	v, err := store.Version(ctx, project.ID, name, n)
+	if err != nil {
+		return StoredPalette{}, err
+	}
+	if v.Deleted {
+		return StoredPalette{}, fmt.Errorf("palette %q was deleted in version %d: %w", name, n, errNotFound)
+	}
+	return StoredPalette{Project: project.ID, Name: name, Version: v.Version, UpdatedAt: v.CreatedAt, ColorSets: *v.Colors}, nil
+}
+
+// requestColors returns the colors of requestPalette.
+func requestColors(r *http.Request) (*ColorSets, error) {
+	p, err := requestPalette(r)
+	if err != nil {
+		return nil, err
+	}
+	return &p.ColorSets, nil
+}
+
+// colorsErrorStatus is the status for an error from requestColors.
+func colorsErrorStatus(err error) int {
+	if errors.Is(err, errNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,731 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PaletteColor is one color of a stored palette.
+type PaletteColor struct {
+	Theme string `json:"theme"`
+	Name  string `json:"name"`
+	Hex   string `json:"hex"`
+}
+
+// paletteColors lists the colors of a theme by name.
+func paletteColors(theme string, m map[string]string) []PaletteColor {
+	colors := make([]PaletteColor, 0, len(m))
+	for name, hex := range m {
+		colors = append(colors, PaletteColor{Theme: theme, Name: name, Hex: hex})
+	}
+	sort.Slice(colors, func(i, j int) bool { return colors[i].Name < colors[j].Name })
+	return colors
+}
+
+// paletteETag is the entity tag of a palette version. Themes and colors
+// share the tag of their palette, so any change to a palette invalidates
+// them all.
+func paletteETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the palette version from If-Match. "*" matches any
+// version, and a tag that is not a palette version matches none.
+func ifMatchVersion(r *http.Request) (version int, present bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return 0, false
+	}
+	if v == "*" {
+		return anyVersion, true
+	}
+	n, err := strconv.Atoi(strings.Trim(v, `"`))
+	if err != nil || n < 1 {
+		return -2, true
+	}
+	return n, true
+}
+
+// requireIfMatch returns the If-Match version, answering 428 when the
+// header is missing so that no change is made without reading first.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		apiError(w, r, "error.preconditionRequired", errors.New("send If-Match with the palette's ETag"), http.StatusPreconditionRequired)
+	}
+	return version, ok
+}
+
+// requestChange describes the change a request makes, from its author and
+// message parameters. message is used when the request gives none, and a
+// signed-in user is always the author.
+func requestChange(r *http.Request, message string) Change {
+	q := r.URL.Query()
+	c := Change{Author: strings.TrimSpace(q.Get("author")), Message: strings.TrimSpace(q.Get("message"))}
+	if p := requestPrincipal(r); auth != nil && p.Method != "anonymous" {
+		c.Author = p.Name
+	}
+	if c.Author == "" {
+		c.Author = "anonymous"
+	}
+	if c.Message == "" {
+		c.Message = message
+	}
+	return c
+}
+
+// requireStore answers 501 when the server runs without -db.
+func requireStore(w http.ResponseWriter, r *http.Request) bool {
+	if store == nil {
+		apiError(w, r, "error.noStore", errors.New("start the server with -db to edit palettes"), http.StatusNotImplemented)
+		return false
+	}
+	return true
+}
+
+// storeProject returns the ID of the project a palette request is about,
+// answering for the request when there is no store or no such project.
+func storeProject(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if !requireStore(w, r) {
+		return "", false
+	}
+	project, err := requestProject(r)
+	if err != nil {
+		storeError(w, r, err)
+		return "", false
+	}
+	return project.ID, true
+}
+
+// storeError answers with the status for an error from the Store.
+func storeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errNotFound):
+		apiError(w, r, "error.notFound", err, http.StatusNotFound)
+	case errors.Is(err, errPaletteExists):
+		apiError(w, r, "error.preconditionRequired", fmt.Errorf("%w; send If-Match to replace it", err), http.StatusPreconditionRequired)
+	case errors.Is(err, errVersionMismatch):
+		apiError(w, r, "error.preconditionFailed", err, http.StatusPreconditionFailed)
+	case errors.Is(err, errInvalidPalette):
+		apiError(w, r, "error.badPalette", err, http.StatusUnprocessableEntity)
+	case errors.Is(err, errProjectExists):
+		apiError(w, r, "error.projectExists", err, http.StatusConflict)
+	case errors.Is(err, errInvalidProject):
+		apiError(w, r, "error.badProject", err, http.StatusUnprocessableEntity)
+	case errors.Is(err, errInvalidWebhook):
+		apiError(w, r, "error.badWebhook", err, http.StatusUnprocessableEntity)
+	default:
+		apiError(w, r, "error.store", err, http.StatusInternalServerError)
+	}
+}
+
+// writeVersioned writes v with the palette's ETag, or answers 304 to a GET
+// whose If-None-Match already has it.
+func writeVersioned(w http.ResponseWriter, r *http.Request, code, version int, v any) {
+	etag := paletteETag(version)
+	w.Header().Set("ETag", etag)
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+			if strings.TrimSpace(tag) == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	writeJSON(w, r, code, v)
+}
+
+// decodeBody decodes a JSON request body of up to 1 MiB into v.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		apiError(w, r, "error.badPalette", err, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allow string) {
+	w.Header().Set("Allow", allow)
+	apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+}
+
+// apiPaletteHandler serves /api/palettes/{palette}. PUT creates a palette,
+// or replaces it when If-Match is given.
+func apiPaletteHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("palette")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, err := store.Get(r.Context(), project, name)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, p.Version, p)
+	case http.MethodPut:
+		if err := validName(name); err != nil {
+			storeError(w, r, err)
+			return
+		}
+		var body ColorSets
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		expected, given := ifMatchVersion(r)
+		if !given {
+			expected = mustCreate
+		}
+		message := "Replace palette"
+		if expected == mustCreate {
+			message = "Create palette"
+		}
+		p, created, err := store.Update(r.Context(), project, name, expected, requestChange(r, message), func(cs *ColorSets) error {
+			*cs = body
+			return nil
+		})
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
+		}
+		writeVersioned(w, r, code, p.Version, p)
+	case http.MethodDelete:
+		expected, ok := requireIfMatch(w, r)
+		if !ok {
+			return
+		}
+		if err := store.Delete(r.Context(), project, name, expected, requestChange(r, "Delete palette")); err != nil {
+			storeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, "GET, PUT, DELETE")
+	}
+}
+
+// themeOf returns the named theme of cs, or errNotFound.
+func themeOf(cs *ColorSets, theme string) (map[string]string, error) {
+	m := cs.theme(theme)
+	if m == nil {
+		return nil, fmt.Errorf("theme %q: %w (must be light or dark)", theme, errNotFound)
+	}
+	return m, nil
+}
+
+// apiThemeHandler serves /api/palettes/{palette}/themes/{theme}, the light
+// or dark colors of a palette as a name to color map. DELETE removes every
+// color of the theme.
+func apiThemeHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	name, theme := r.PathValue("palette"), r.PathValue("theme")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, err := store.Get(r.Context(), project, name)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		m, err := themeOf(&p.ColorSets, theme)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, p.Version, m)
+	case http.MethodPut, http.MethodDelete:
+		body := map[string]string{}
+		if r.Method == http.MethodPut && !decodeBody(w, r, &body) {
+			return
+		}
+		expected, ok := requireIfMatch(w, r)
+		if !ok {
+			return
+		}
+		message := "Replace " + theme + " theme"
+		if r.Method == http.MethodDelete {
+			message = "Clear " + theme + " theme"
+		}
+		p, _, err := store.Update(r.Context(), project, name, expected, requestChange(r, message), func(cs *ColorSets) error {
+			m, err := themeOf(cs, theme)
+			if err != nil {
+				return err
+			}
+			clear(m)
+			for color, hex := range body {
+				m[color] = hex
+			}
+			return nil
+		})
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.Header().Set("ETag", paletteETag(p.Version))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, p.Version, p.theme(theme))
+	default:
+		methodNotAllowed(w, r, "GET, PUT, DELETE")
+	}
+}
+
+// ColorValue is the body of a PUT of one color.
+type ColorValue struct {
+	Hex string `json:"hex"`
+}
+
+// apiColorHandler serves /api/palettes/{palette}/themes/{theme}/colors/{color}.
+// PUT takes {"hex": value}, where the value may be any opaque CSS color and
+// is stored as #rrggbb.
+func apiColorHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	name, theme, color := r.PathValue("palette"), r.PathValue("theme"), r.PathValue("color")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, err := store.Get(r.Context(), project, name)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		m, err := themeOf(&p.ColorSets, theme)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		hex, ok := m[color]
+		if !ok {
+			storeError(w, r, fmt.Errorf("color %q: %w", color, errNotFound))
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, p.Version, PaletteColor{Theme: theme, Name: color, Hex: hex})
+	case http.MethodPut, http.MethodDelete:
+		var body ColorValue
+		if r.Method == http.MethodPut && !decodeBody(w, r, &body) {
+			return
+		}
+		expected, ok := requireIfMatch(w, r)
+		if !ok {
+			return
+		}
+		message := fmt.Sprintf("Set %s/%s to %s", theme, color, body.Hex)
+		if r.Method == http.MethodDelete {
+			message = fmt.Sprintf("Delete %s/%s", theme, color)
+		}
+		created := false
+		p, _, err := store.Update(r.Context(), project, name, expected, requestChange(r, message), func(cs *ColorSets) error {
+			m, err := themeOf(cs, theme)
+			if err != nil {
+				return err
+			}
+			_, exists := m[color]
+			if r.Method == http.MethodDelete {
+				if !exists {
+					return fmt.Errorf("color %q: %w", color, errNotFound)
+				}
+				delete(m, color)
+				return nil
+			}
+			created = !exists
+			m[color] = body.Hex
+			return nil
+		})
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.Header().Set("ETag", paletteETag(p.Version))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
+		}
+		writeVersioned(w, r, code, p.Version, PaletteColor{Theme: theme, Name: color, Hex: p.theme(theme)[color]})
+	default:
+		methodNotAllowed(w, r, "GET, PUT, DELETE")
+	}
+}
+
+// apiVersionsHandler serves /api/palettes/{palette}/versions, the history of
+// a palette, newest first and without colors.
+func apiVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+	versions, err := store.Versions(r.Context(), project, r.PathValue("palette"))
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, versions)
+}
+
+// pathVersion reads the {version} path value.
+func pathVersion(r *http.Request) (int, error) {
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("version %q: %w", r.PathValue("version"), errNotFound)
+	}
+	return version, nil
+}
+
+// apiVersionHandler serves /api/palettes/{palette}/versions/{version}, one
+// version with its colors. The full report of a version is the usual
+// results with a version parameter.
+func apiVersionHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+	version, err := pathVersion(r)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	v, err := store.Version(r.Context(), project, r.PathValue("palette"), version)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, v)
+}
+
+// apiRollbackHandler serves POST /api/palettes/{palette}/versions/{version}/rollback,
+// which saves the colors of an earlier version as the next version. Like a
+// PUT of the whole palette, it needs If-Match unless the palette has been
+// deleted.
+func apiRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, "POST")
+		return
+	}
+	name := r.PathValue("palette")
+	version, err := pathVersion(r)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	v, err := store.Version(r.Context(), project, name, version)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	if v.Deleted {
+		storeError(w, r, fmt.Errorf("%w: version %d deleted the palette", errInvalidPalette, version))
+		return
+	}
+	expected, given := ifMatchVersion(r)
+	if !given {
+		expected = mustCreate
+	}
+	change := requestChange(r, fmt.Sprintf("Roll back to version %d", version))
+	p, _, err := store.Update(r.Context(), project, name, expected, change, func(cs *ColorSets) error {
+		*cs = *v.Colors
+		return nil
+	})
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	writeVersioned(w, r, http.StatusOK, p.Version, p)
+}
+
+// apiProjectsHandler lists the projects on GET and creates one from a
+// Project body on POST.
+func apiProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireStore(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		projects, err := store.Projects(r.Context())
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, projects)
+	case http.MethodPost:
+		var body Project
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		p, _, err := store.SaveProject(r.Context(), body, true)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/api/projects/"+p.ID)
+		writeJSON(w, r, http.StatusCreated, p)
+	default:
+		methodNotAllowed(w, r, "GET, POST")
+	}
+}
+
+// ProjectUpdate is the body of a PUT of a project.
+type ProjectUpdate struct {
+	Name     string          `json:"name"`
+	Settings ProjectSettings `json:"settings"`
+}
+
+// apiProjectHandler serves /api/projects/{project}. PUT takes the name and
+// settings and creates the project if needed; DELETE removes the project
+// with all of its palettes.
+func apiProjectHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireStore(w, r) {
+		return
+	}
+	id := r.PathValue("project")
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, err := store.Project(r.Context(), id)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, p)
+	case http.MethodPut:
+		var body ProjectUpdate
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		p, created, err := store.SaveProject(r.Context(), Project{ID: id, Name: body.Name, Settings: body.Settings}, false)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
+		}
+		writeJSON(w, r, code, p)
+	case http.MethodDelete:
+		if err := store.DeleteProject(r.Context(), id); err != nil {
+			storeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, "GET, PUT, DELETE")
+	}
+}
+
+// ProjectExport is a project with every one of its palettes.
+type ProjectExport struct {
+	Project
+	Palettes []StoredPalette `json:"palettes"`
+}
+
+// apiExportHandler serves /api/export, the project of the request with all
+// of its palettes as one JSON file.
+func apiExportHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireStore(w, r) {
+		return
+	}
+	project, err := requestProject(r)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	palettes, err := store.List(r.Context(), project.ID)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=%s.json", project.ID))
+	writeJSON(w, r, http.StatusOK, ProjectExport{Project: project, Palettes: palettes})
+}
+
+// apiCheckHandler serves /api/check, which holds the palette of the request
+// to its project's thresholds for use in CI. It answers 200 when the check
+// passes and 422 when it fails, so that curl --fail fails the build.
+func apiCheckHandler(w http.ResponseWriter, r *http.Request) {
+	project, err := requestProject(r)
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+	v := r.URL.Query()
+	q, err := projectResultQuery(v, project)
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+	palette, err := findPalette(r.Context(), project, v.Get("palette"), v.Get("version"))
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+	check := checkProject(r.Context(), project, palette, q.Algorithm)
+	code := http.StatusOK
+	if !check.Pass {
+		code = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, r, code, check)
+}
+
+// webhookID parses the {webhook} path value.
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("webhook"), 10, 64)
+	if err != nil || id <= 0 {
+		apiError(w, r, "error.notFound", fmt.Errorf("webhook %q: %w", r.PathValue("webhook"), errNotFound), http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+// WebhookSettings is what clients send to create or change a webhook.
+type WebhookSettings struct {
+	URL             string   `json:"url"`
+	Secret          string   `json:"secret"`
+	Palettes        []string `json:"palettes"`
+	RegressionsOnly bool     `json:"regressionsOnly"`
+	Disabled        bool     `json:"disabled"`
+}
+
+func (b WebhookSettings) webhook(project string, id int64) Webhook {
+	return Webhook{ID: id, Project: project, URL: b.URL, Secret: b.Secret, Palettes: b.Palettes, RegressionsOnly: b.RegressionsOnly, Disabled: b.Disabled}
+}
+
+// apiWebhooksHandler serves /api/webhooks: GET lists the project's
+// webhooks and POST adds one, answering with its secret.
+func apiWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		hooks, err := store.Webhooks(r.Context(), project)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, hooks)
+	case http.MethodPost:
+		var body WebhookSettings
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		h, err := store.CreateWebhook(r.Context(), body.webhook(project, 0))
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("%s/api/webhooks/%d", Project{ID: project}.base(), h.ID))
+		writeJSON(w, r, http.StatusCreated, h)
+	default:
+		methodNotAllowed(w, r, "GET, POST")
+	}
+}
+
+// apiWebhookHandler serves /api/webhooks/{webhook}. PUT replaces the
+// settings of the webhook and keeps its secret unless one is given.
+func apiWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h, err := store.Webhook(r.Context(), project, id)
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, h)
+	case http.MethodPut:
+		var body WebhookSettings
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		h, err := store.UpdateWebhook(r.Context(), body.webhook(project, id))
+		if err != nil {
+			storeError(w, r, err)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, h)
+	case http.MethodDelete:
+		if err := store.DeleteWebhook(r.Context(), project, id); err != nil {
+			storeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, "GET, PUT, DELETE")
+	}
+}
+
+// apiDeliveriesHandler serves /api/webhooks/{webhook}/deliveries, the
+// newest deliveries first, up to the limit parameter.
+func apiDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+	project, ok := storeProject(w, r)
+	if !ok {
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	limit := 50
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > deliveriesKept {
+			apiError(w, r, "error.badQuery", fmt.Errorf("limit must be 1 to %d", deliveriesKept), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	deliveries, err := store.Deliveries(r.Context(), project, id, limit)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, deliveries)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// SurfaceReport is how one foreground does on a set of backgrounds, such
+// as the base, raised, and overlay surfaces it appears on. The limiting
+// surface is the one with the lowest ratio, so its levels are the levels
+// the foreground reaches on every surface.
+type SurfaceReport struct {
+	ForegroundName string  `json:"foregroundName"`
+	ForegroundHex  string  `json:"foregroundHex"`
+	MinRatio       float64 `json:"minRatio"`
+	MinRatioRaw    float64 `json:"minRatioRaw"`
+	MaxRatio       float64 `json:"maxRatio"`
+	MaxRatioRaw    float64 `json:"maxRatioRaw"`
+	// Limiting names the surface with the lowest ratio, the first in the
+	// order checked when several tie.
+	Limiting string          `json:"limiting"`
+	Category Category        `json:"category"`
+	Surfaces []SurfaceResult `json:"surfaces"`
+}
+
+// SurfaceResult is the foreground on one surface.
+type SurfaceResult struct {
+	ContrastResult
+	// Limiting is set on every surface with the lowest ratio.
+	Limiting bool `json:"limiting"`
+}
+
+// surfaceBackgrounds resolves the surfaces to check, in this order: the
+// dark palette colors whose names match group, a glob as in fg: search
+// terms, sorted by name, followed by the refs resolved as iconBackgrounds
+// does, in the order given. A surface named more than once is checked
+// once. Neither means every dark palette color.
+func surfaceBackgrounds(colors *ColorSets, refs []string, group string) ([]IconBackground, error) {
+	if group == "" {
+		bgs, err := iconBackgrounds(colors, refs)
+		return uniqueBackgrounds(bgs), err
+	}
+	pattern, err := globPattern(group)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range colors.Dark {
+		if globMatch(pattern, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no dark palette color matches %q", group)
+	}
+	sort.Strings(names)
+	bgs, err := iconBackgrounds(colors, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) > 0 {
+		more, err := iconBackgrounds(colors, refs)
+		if err != nil {
+			return nil, err
+		}
+		bgs = append(bgs, more...)
+	}
+	return uniqueBackgrounds(bgs), nil
+}
+
+// uniqueBackgrounds drops the backgrounds whose name came before.
+func uniqueBackgrounds(bgs []IconBackground) []IconBackground {
+	seen := map[string]bool{}
+	var unique []IconBackground
+	for _, bg := range bgs {
+		if !seen[bg.Name] {
+			seen[bg.Name] = true
+			unique = append(unique, bg)
+		}
+	}
+	return unique
+}
+
+// checkSurfaces checks the light palette color or #rrggbb value ref on
+// every background.
+func checkSurfaces(colors *ColorSets, ref string, backgrounds []IconBackground) (SurfaceReport, error) {
+	fgName, fgHex, ok := resolveUsageColor(colors.Light, ref)
+	if !ok {
+		return SurfaceReport{}, fmt.Errorf("unknown foreground %q", ref)
+	}
+	if _, err := hexRGBA(fgHex); err != nil {
+		return SurfaceReport{}, fmt.Errorf("foreground %q: %w", ref, err)
+	}
+	if len(backgrounds) == 0 {
+		return SurfaceReport{}, errors.New("no surfaces to check")
+	}
+
+	report := SurfaceReport{ForegroundName: fgName, ForegroundHex: fgHex}
+	for _, bg := range backgrounds {
+		ratio, err := contrastRatio(fgHex, bg.Hex)
+		if err != nil {
+			return SurfaceReport{}, fmt.Errorf("background %q: %w", bg.Name, err)
+		}
+		report.Surfaces = append(report.Surfaces, SurfaceResult{ContrastResult: newContrastResult(fgName, fgHex, bg.Name, bg.Hex, ratio)})
+	}
+
+	limiting, best := report.Surfaces[0].ContrastResult, report.Surfaces[0].ContrastResult
+	for _, s := range report.Surfaces[1:] {
+		if s.ContrastRatioRaw < limiting.ContrastRatioRaw {
+			limiting = s.ContrastResult
+		}
+		if s.ContrastRatioRaw > best.ContrastRatioRaw {
+			best = s.ContrastResult
+		}
+	}
+	for i := range report.Surfaces {
+		report.Surfaces[i].Limiting = report.Surfaces[i].ContrastRatioRaw == limiting.ContrastRatioRaw
+	}
+	report.MinRatio, report.MinRatioRaw = limiting.ContrastRatio, limiting.ContrastRatioRaw
+	report.MaxRatio, report.MaxRatioRaw = best.ContrastRatio, best.ContrastRatioRaw
+	report.Limiting, report.Category = limiting.BackgroundName, limiting.Category
+	return report, nil
+}
+
+// apiSurfacesHandler checks the foreground given by foreground on the
+// backgrounds given by bg and group, or every dark palette color.
+func apiSurfacesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet)
+		apiError(w, r, "error.method", errors.New(r.Method+" not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	colors, err := requestColors(r)
+	if err != nil {
+		apiError(w, r, "error.loadColors", err, colorsErrorStatus(err))
+		return
+	}
+	v := r.URL.Query()
+	backgrounds, err := surfaceBackgrounds(colors, v["bg"], v.Get("group"))
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+	report, err := checkSurfaces(colors, v.Get("foreground"), backgrounds)
+	if err != nil {
+		apiError(w, r, "error.badQuery", err, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
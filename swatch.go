@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// SwatchOptions control how swatch images are drawn.
+type SwatchOptions struct {
+	// Font is an image font: regular, bold, or mono.
+	Font string
+	// SmallPx and LargePx are the sizes of the two text samples.
+	SmallPx float64
+	LargePx float64
+	// Width is the width of one swatch card.
+	Width int
+	// Columns is the number of cards per row in a palette sheet.
+	Columns int
+	// Text is the sample text.
+	Text string
+}
+
+// Geometry of swatch cards, in pixels.
+const (
+	swatchPad      = 12
+	swatchGap      = 16
+	swatchBadgeH   = 22
+	swatchCaptionH = 24
+)
+
+var (
+	swatchBadge   = textStyle{Font: "bold", Size: 12}
+	swatchCaption = textStyle{Font: "regular", Size: 12}
+	swatchSize    = textStyle{Font: "mono", Size: 11, End: true}
+)
+
+func parseBoundedInt(v url.Values, name string, def, lo, hi int) (int, error) {
+	s := v.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("invalid %s %q (must be %d-%d)", name, s, lo, hi)
+	}
+	return n, nil
+}
+
+// parseSwatchOptions reads font, small, large, width, columns, and text,
+// applying defaults for anything left out.
+func parseSwatchOptions(v url.Values) (SwatchOptions, error) {
+	o := SwatchOptions{Font: v.Get("font"), Text: v.Get("text")}
+	if o.Font == "" {
+		o.Font = "regular"
+	}
+	if _, ok := imageFonts[o.Font]; !ok {
+		return o, fmt.Errorf("invalid font %q (must be regular, bold, or mono)", o.Font)
+	}
+	if o.Text == "" {
+		o.Text = "The quick brown fox"
+	}
+	small, err := parseBoundedInt(v, "small", 16, 8, 72)
+	if err != nil {
+		return o, err
+	}
+	large, err := parseBoundedInt(v, "large", 24, 8, 144)
+	if err != nil {
+		return o, err
+	}
+	o.SmallPx, o.LargePx = float64(small), float64(large)
+	if o.Width, err = parseBoundedInt(v, "width", 360, 120, 2000); err != nil {
+		return o, err
+	}
+	if o.Columns, err = parseBoundedInt(v, "columns", 3, 1, 12); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// pairResult classifies one pair given by palette names or #rrggbb values.
+// Foreground names refer to the light palette and background names to the
+// dark palette, as in usage rules.
+func pairResult(colors *ColorSets, fgRef, bgRef string) (ContrastResult, error) {
+	fgName, fgHex, ok := resolveUsageColor(colors.Light, fgRef)
+	if !ok {
+		return ContrastResult{}, fmt.Errorf("unknown foreground %q", fgRef)
+	}
+	bgName, bgHex, ok := resolveUsageColor(colors.Dark, bgRef)
+	if !ok {
+		return ContrastResult{}, fmt.Errorf("unknown background %q", bgRef)
+	}
+	ratio, err := contrastRatio(fgHex, bgHex)
+	if err != nil {
+		return ContrastResult{}, err
+	}
+	return newContrastResult(fgName, fgHex, bgName, bgHex, ratio), nil
+}
+
+func (o SwatchOptions) cardHeight() int {
+	return swatchPad + int(1.25*o.SmallPx) + int(1.25*o.LargePx) + 10 + swatchBadgeH + swatchPad
+}
+
+// drawSwatch draws the card for r with its top left corner at (x, y) and
+// its caption below it. Labels are not translated because the Go fonts only
+// cover Latin, Greek, and Cyrillic scripts.
+func drawSwatch(c canvas, x, y int, r ContrastResult, o SwatchOptions) {
+	card := image.Rect(x, y, x+o.Width, y+o.cardHeight())
+	title := fmt.Sprintf("%s on %s: %.2f", r.ForegroundName, r.BackgroundName, r.ContrastRatio)
+	c.clip(card, title, func() {
+		c.rect(card, r.BackgroundHex)
+
+		baseline := y + swatchPad + int(o.SmallPx)
+		c.text(x+swatchPad, baseline, textStyle{Font: o.Font, Size: o.SmallPx}, r.ForegroundHex, o.Text)
+		c.text(card.Max.X-swatchPad, y+swatchPad+10, swatchSize, r.ForegroundHex, fmt.Sprintf("%gpx", o.SmallPx))
+		baseline += int(1.25 * o.LargePx)
+		c.text(x+swatchPad, baseline, textStyle{Font: o.Font, Size: o.LargePx}, r.ForegroundHex, o.Text)
+		c.text(card.Max.X-swatchPad, baseline-int(o.LargePx)+10, swatchSize, r.ForegroundHex, fmt.Sprintf("%gpx", o.LargePx))
+
+		badges := []struct {
+			label, fill string
+		}{
+			{fmt.Sprintf("%.2f:1", r.ContrastRatio), matrixInk},
+			{"Small " + r.LevelSmallText.String(), levelColors[r.LevelSmallText]},
+			{"Large " + r.LevelLargeText.String(), levelColors[r.LevelLargeText]},
+			{"UI " + r.LevelNonText.String(), levelColors[r.LevelNonText]},
+		}
+		bx, by := x+swatchPad, card.Max.Y-swatchPad-swatchBadgeH
+		for _, b := range badges {
+			w := textWidth(swatchBadge.Font, swatchBadge.Size, b.label) + 12
+			c.rect(image.Rect(bx, by, bx+w, by+swatchBadgeH), b.fill)
+			c.text(bx+6, by+swatchBadgeH-6, swatchBadge, inkFor(b.fill), b.label)
+			bx += w + 6
+		}
+	})
+	caption := fmt.Sprintf("%s (%s) / %s (%s)", r.ForegroundName, r.ForegroundHex, r.BackgroundName, r.BackgroundHex)
+	c.text(x, card.Max.Y+16, swatchCaption, matrixInk, caption)
+}
+
+// swatchesSize returns the size of a sheet of count swatch cards and the
+// number of cards per row.
+func swatchesSize(count int, o SwatchOptions) (width, height, columns int) {
+	columns = o.Columns
+	if count < columns {
+		columns = max(count, 1)
+	}
+	rows := (count + columns - 1) / columns
+	width = swatchGap + columns*(o.Width+swatchGap)
+	height = swatchGap + max(rows, 1)*(o.cardHeight()+swatchCaptionH+swatchGap)
+	return width, height, columns
+}
+
+// renderSwatches draws results as a sheet of swatch cards in "svg" or "png"
+// format. A single result gives a single card.
+func renderSwatches(w io.Writer, format string, results []ContrastResult, o SwatchOptions) error {
+	width, height, columns := swatchesSize(len(results), o)
+	cellH := o.cardHeight() + swatchCaptionH
+
+	return renderImage(w, format, width, height, func(c canvas) {
+		c.rect(image.Rect(0, 0, width, height), matrixPaper)
+		for i, r := range results {
+			x := swatchGap + (i%columns)*(o.Width+swatchGap)
+			y := swatchGap + (i/columns)*(cellH+swatchGap)
+			drawSwatch(c, x, y, r, o)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Font sizes at which WCAG treats text as large: 18pt, or 14pt when bold.
+const (
+	largeTextPx     = 24
+	largeBoldTextPx = 14 * 4.0 / 3.0
+	boldWeight      = 700
+	remPx           = 16
+)
+
+// Algorithms that can judge a usage.
+const (
+	AlgorithmWCAG = "wcag"
+	AlgorithmAPCA = "apca"
+)
+
+// UsageRule declares where a foreground and background are used together,
+// so the engine can pick the one threshold that applies. Foreground names
+// refer to the light palette and background names to the dark palette; a
+// "#rrggbb" value may be used instead of a name.
+type UsageRule struct {
+	Name       string `json:"name"`
+	Foreground string `json:"foreground"`
+	Background string `json:"background"`
+	// FontSize is a CSS length in px, pt, or rem. A bare number is px.
+	FontSize   string `json:"fontSize,omitempty"`
+	FontWeight int    `json:"fontWeight,omitempty"`
+	// NonText marks icons, borders, and other UI parts rather than text.
+	NonText bool `json:"nonText,omitempty"`
+}
+
+// UsageResult is the single verdict for a usage rule.
+type UsageResult struct {
+	Name           string  `json:"name"`
+	ForegroundName string  `json:"foregroundName"`
+	ForegroundHex  string  `json:"foregroundHex"`
+	BackgroundName string  `json:"backgroundName"`
+	BackgroundHex  string  `json:"backgroundHex"`
+	FontSizePx     float64 `json:"fontSizePx,omitempty"`
+	FontWeight     int     `json:"fontWeight,omitempty"`
+	Target         Target  `json:"target"`
+	Algorithm      string  `json:"algorithm"`
+	// Score is the WCAG display ratio or, for APCA, the absolute Lc value
+	// truncated to one decimal. ScoreRaw decided Pass.
+	Score    float64 `json:"score"`
+	ScoreRaw float64 `json:"scoreRaw"`
+	Required float64 `json:"required"`
+	// Level is only set for WCAG, where it is the level reached for Target.
+	Level *Level `json:"level,omitempty"`
+	Pass  bool   `json:"pass"`
+}
+
+// parseFontSize converts a CSS length in px, pt, or rem to pixels.
+func parseFontSize(value string) (float64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(s, "px"):
+		s = strings.TrimSuffix(s, "px")
+	case strings.HasSuffix(s, "pt"):
+		s = strings.TrimSuffix(s, "pt")
+		scale = 4.0 / 3.0
+	case strings.HasSuffix(s, "rem"):
+		s = strings.TrimSuffix(s, "rem")
+		scale = remPx
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid font size %q", value)
+	}
+	return v * scale, nil
+}
+
+// textTarget decides whether WCAG's large-text thresholds apply.
+func textTarget(sizePx float64, weight int) Target {
+	if sizePx >= largeTextPx || (sizePx >= largeBoldTextPx && weight >= boldWeight) {
+		return TargetLargeText
+	}
+	return TargetSmallText
+}
+
+// aaThreshold is the ratio needed to reach AA for target.
+func aaThreshold(target Target) float64 {
+	for _, sc := range successCriteria {
+		if sc.Level != LevelAA {
+			continue
+		}
+		for _, t := range sc.Thresholds {
+			if t.Target == target {
+				return t.Ratio
+			}
+		}
+	}
+	return 0
+}
+
+func resolveUsageColor(palette map[string]string, ref string) (name, hex string, ok bool) {
+	if strings.HasPrefix(ref, "#") {
+		return ref, ref, true
+	}
+	hex, ok = palette[ref]
+	return ref, hex, ok
+}
+
+// evaluateUsages judges each usage rule with the chosen algorithm. Rules
+// that cannot be evaluated are returned as warnings.
+func evaluateUsages(ctx context.Context, colors *ColorSets, algorithm string) ([]UsageResult, []PaletteWarning) {
+	var results []UsageResult
+	var warnings []PaletteWarning
+	warn := func(rule UsageRule, msg string) {
+		slog.WarnContext(ctx, "invalid usage rule", "usage", rule.Name, "problem", msg)
+		warnings = append(warnings, PaletteWarning{Theme: "usages", Name: rule.Name, Value: rule.Foreground + " / " + rule.Background, Message: msg})
+	}
+
+	for _, rule := range colors.Usages {
+		fgName, fgHex, ok := resolveUsageColor(colors.Light, rule.Foreground)
+		if !ok {
+			warn(rule, fmt.Sprintf("unknown foreground %q", rule.Foreground))
+			continue
+		}
+		bgName, bgHex, ok := resolveUsageColor(colors.Dark, rule.Background)
+		if !ok {
+			warn(rule, fmt.Sprintf("unknown background %q", rule.Background))
+			continue
+		}
+
+		result := UsageResult{
+			Name:           rule.Name,
+			ForegroundName: fgName,
+			ForegroundHex:  fgHex,
+			BackgroundName: bgName,
+			BackgroundHex:  bgHex,
+			FontWeight:     rule.FontWeight,
+			Target:         TargetNonText,
+			Algorithm:      algorithm,
+		}
+		if result.FontWeight == 0 {
+			result.FontWeight = 400
+		}
+		if !rule.NonText {
+			sizePx, err := parseFontSize(rule.FontSize)
+			if err != nil {
+				warn(rule, err.Error())
+				continue
+			}
+			result.FontSizePx = sizePx
+			result.Target = textTarget(sizePx, result.FontWeight)
+		}
+
+		switch algorithm {
+		case AlgorithmAPCA:
+			lc, err := apcaContrast(fgHex, bgHex)
+			if err != nil {
+				warn(rule, "not a valid #rrggbb color")
+				continue
+			}
+			result.ScoreRaw = math.Abs(lc)
+			result.Score = math.Trunc(result.ScoreRaw*10) / 10
+			result.Required = apcaRequiredLc(result.Target, result.FontSizePx, result.FontWeight)
+			result.Pass = result.ScoreRaw >= result.Required
+		default:
+			ratio, err := contrastRatio(fgHex, bgHex)
+			if err != nil {
+				warn(rule, "not a valid #rrggbb color")
+				continue
+			}
+			level := levelFor(result.Target, ratio)
+			result.ScoreRaw = ratio
+			result.Score = DisplayRatio(ratio)
+			result.Required = aaThreshold(result.Target)
+			result.Level = &level
+			result.Pass = level >= LevelAA
+		}
+		results = append(results, result)
+	}
+	return results, warnings
+}
+
+func validAlgorithm(algorithm string) bool {
+	return algorithm == AlgorithmWCAG || algorithm == AlgorithmAPCA
+}
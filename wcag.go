@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is a WCAG conformance level. Levels are ordered, so a higher value
+// is a stricter level.
+type Level int
+
+const (
+	LevelFail Level = iota
+	LevelAA
+	LevelAAA
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelAAA:
+		return "AAA"
+	case LevelAA:
+		return "AA"
+	default:
+		return "Fail"
+	}
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// ParseLevel accepts AAA, AA, or Fail in any case.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "AAA":
+		return LevelAAA, nil
+	case "AA":
+		return LevelAA, nil
+	case "FAIL":
+		return LevelFail, nil
+	default:
+		return LevelFail, fmt.Errorf("invalid level %q", s)
+	}
+}
+
+// Category is the single group a result is listed under. Categories are
+// ordered from worst to best.
+type Category int
+
+const (
+	CategoryFail Category = iota
+	CategoryAALarge
+	CategoryAA
+	CategoryAAA
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryAAA:
+		return "AAA"
+	case CategoryAA:
+		return "AA"
+	case CategoryAALarge:
+		return "AALarge"
+	default:
+		return "Fail"
+	}
+}
+
+func (c Category) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Category) UnmarshalText(text []byte) error {
+	parsed, err := ParseCategory(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// ParseCategory accepts AAA, AA, AALarge, or Fail in any case.
+func ParseCategory(s string) (Category, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "AAA":
+		return CategoryAAA, nil
+	case "AA":
+		return CategoryAA, nil
+	case "AALARGE":
+		return CategoryAALarge, nil
+	case "FAIL":
+		return CategoryFail, nil
+	default:
+		return CategoryFail, fmt.Errorf("invalid category %q", s)
+	}
+}
+
+// Target is the kind of content a contrast threshold applies to.
+type Target string
+
+const (
+	TargetSmallText Target = "smallText"
+	TargetLargeText Target = "largeText"
+	TargetNonText   Target = "nonText"
+)
+
+// Threshold is the minimum contrast ratio a success criterion requires for
+// one kind of content.
+type Threshold struct {
+	Target Target  `json:"target"`
+	Ratio  float64 `json:"ratio"`
+}
+
+// SuccessCriterion is a WCAG 2.2 success criterion about contrast.
+type SuccessCriterion struct {
+	ID         string
+	Name       string
+	Level      Level
+	Thresholds []Threshold
+}
+
+// successCriteria is the source of every threshold used to classify a pair.
+var successCriteria = []SuccessCriterion{
+	{
+		ID:    "1.4.3",
+		Name:  "Contrast (Minimum)",
+		Level: LevelAA,
+		Thresholds: []Threshold{
+			{Target: TargetSmallText, Ratio: 4.5},
+			{Target: TargetLargeText, Ratio: 3},
+		},
+	},
+	{
+		ID:    "1.4.6",
+		Name:  "Contrast (Enhanced)",
+		Level: LevelAAA,
+		Thresholds: []Threshold{
+			{Target: TargetSmallText, Ratio: 7},
+			{Target: TargetLargeText, Ratio: 4.5},
+		},
+	},
+	{
+		ID:    "1.4.11",
+		Name:  "Non-text Contrast",
+		Level: LevelAA,
+		Thresholds: []Threshold{
+			{Target: TargetNonText, Ratio: 3},
+		},
+	},
+}
+
+// CriterionResult is the outcome of one success criterion for a pair. A
+// criterion has a threshold per kind of content, so there is no single
+// outcome: a 3.5:1 pair meets 1.4.3 as large text but not as small text.
+// Checks holds the outcome for each.
+type CriterionResult struct {
+	ID     string           `json:"id"`
+	Name   string           `json:"name"`
+	Level  Level            `json:"level"`
+	Checks []ThresholdCheck `json:"checks"`
+}
+
+// ThresholdCheck records whether a ratio met one threshold.
+type ThresholdCheck struct {
+	Threshold
+	Pass bool `json:"pass"`
+}
+
+// evaluateCriteria checks ratio against every threshold of every success
+// criterion.
+func evaluateCriteria(ratio float64) []CriterionResult {
+	results := make([]CriterionResult, 0, len(successCriteria))
+	for _, sc := range successCriteria {
+		result := CriterionResult{ID: sc.ID, Name: sc.Name, Level: sc.Level}
+		for _, t := range sc.Thresholds {
+			result.Checks = append(result.Checks, ThresholdCheck{Threshold: t, Pass: ratio >= t.Ratio})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// levelFor returns the highest level whose threshold for target ratio meets.
+func levelFor(target Target, ratio float64) Level {
+	best := LevelFail
+	for _, sc := range successCriteria {
+		for _, t := range sc.Thresholds {
+			if t.Target == target && ratio >= t.Ratio && sc.Level > best {
+				best = sc.Level
+			}
+		}
+	}
+	return best
+}
+
+func complianceLevel(ratio float64) Level {
+	return levelFor(TargetSmallText, ratio)
+}
+
+func complianceLevelLarge(ratio float64) Level {
+	return levelFor(TargetLargeText, ratio)
+}
+
+// complianceLevelNonText is the level for UI components and graphics, which
+// WCAG only defines at AA.
+func complianceLevelNonText(ratio float64) Level {
+	return levelFor(TargetNonText, ratio)
+}
+
+// resultCategory places a pair in the single group the page lists it under.
+// AALarge covers pairs that pass AA for large text but not for small text.
+func resultCategory(small, large Level) Category {
+	switch {
+	case small == LevelAAA:
+		return CategoryAAA
+	case small == LevelAA:
+		return CategoryAA
+	case large >= LevelAA:
+		return CategoryAALarge
+	default:
+		return CategoryFail
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// The UI ships inside the binary. Files under -web-dir with the same relative
+// path (templates/index.html, static/style.css, locales/en.json, ...) take
+// precedence, so the page can be rebranded without rebuilding.
+//
+//go:embed web
+var embeddedWeb embed.FS
+
+var (
+	pageTemplates *template.Template
+	staticFiles   http.Handler
+)
+
+// overlayFS opens files from override first and falls back to base.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if o.override != nil {
+		f, err := o.override.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return o.base.Open(name)
+}
+
+// loadWeb parses the page templates and message catalogs once and prepares
+// the static file handler. overrideDir may be empty.
+func loadWeb(overrideDir string) error {
+	base, err := fs.Sub(embeddedWeb, "web")
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("").ParseFS(base, "templates/*.html")
+	if err != nil {
+		return fmt.Errorf("parse embedded templates: %w", err)
+	}
+
+	assets := overlayFS{base: base}
+	var override fs.FS
+	if overrideDir != "" {
+		info, err := os.Stat(overrideDir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", overrideDir)
+		}
+		override = os.DirFS(overrideDir)
+		assets.override = override
+
+		matches, err := fs.Glob(override, "templates/*.html")
+		if err != nil {
+			return err
+		}
+		if len(matches) > 0 {
+			tmpl, err = tmpl.ParseFS(override, matches...)
+			if err != nil {
+				return fmt.Errorf("parse templates in %s: %w", overrideDir, err)
+			}
+		}
+	}
+
+	if err := loadCatalogs(base, override); err != nil {
+		return err
+	}
+
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		return err
+	}
+
+	pageTemplates = tmpl
+	staticFiles = http.FileServer(http.FS(static))
+	return nil
+}
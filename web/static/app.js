@@ -0,0 +1,451 @@
+const themeToggleBtn = document.getElementById('theme-toggle');
+const currentTheme = localStorage.getItem('theme') ? localStorage.getItem('theme') : null;
+
+if (currentTheme) {
+    document.documentElement.classList.add(currentTheme);
+    if (currentTheme === 'dark') {
+        themeToggleBtn.textContent = '☀️';
+    } else {
+        themeToggleBtn.textContent = '🌙';
+    }
+}
+
+themeToggleBtn.addEventListener('click', () => {
+    document.documentElement.classList.toggle('dark');
+    let theme = 'light';
+    if (document.documentElement.classList.contains('dark')) {
+        theme = 'dark';
+        themeToggleBtn.textContent = '☀️';
+    } else {
+        theme = 'light';
+        themeToggleBtn.textContent = '🌙';
+    }
+    localStorage.setItem('theme', theme);
+    showToast(theme === 'dark' ? document.body.dataset.toastDark : document.body.dataset.toastLight);
+});
+
+const languageSelect = document.getElementById('language-select');
+
+languageSelect.addEventListener('change', function() {
+    const url = new URL(window.location.href);
+    url.searchParams.set('lang', this.value);
+    window.location.href = url.toString();
+});
+
+const projectSelect = document.getElementById('project-select');
+
+// Each project has its own pages under /projects/{id}/; the default
+// project is served at the root.
+if (projectSelect) {
+    projectSelect.addEventListener('change', function() {
+        const url = new URL(window.location.href);
+        url.pathname = this.value === 'default' ? '/' : '/projects/' + encodeURIComponent(this.value) + '/';
+        url.search = '';
+        url.hash = '';
+        const lang = new URL(window.location.href).searchParams.get('lang');
+        if (lang) {
+            url.searchParams.set('lang', lang);
+        }
+        window.location.href = url.toString();
+    });
+}
+
+const searchInput = document.getElementById('search-input');
+
+searchInput.addEventListener('change', function() {
+    const url = new URL(window.location.href);
+    url.searchParams.delete('search');
+    window.history.replaceState(null, '', url.toString());
+    setQueryParam('q', this.value);
+});
+
+const filterSelect = document.getElementById('filter-select');
+
+filterSelect.addEventListener('change', function() {
+    setQueryParam('filter', this.value);
+});
+
+document.getElementById('sort-select').addEventListener('change', function() {
+    setQueryParam('sort', this.value);
+});
+
+document.getElementById('order-select').addEventListener('change', function() {
+    setQueryParam('order', this.value);
+});
+
+document.getElementById('view-select').addEventListener('change', function() {
+    setQueryParam('view', this.value === 'list' ? '' : this.value);
+});
+
+const algorithmSelect = document.getElementById('algorithm-select');
+
+if (algorithmSelect) {
+    algorithmSelect.addEventListener('change', function() {
+        setQueryParam('algorithm', this.value);
+    });
+}
+
+// Reloads the page with one query parameter changed, starting again from
+// the first page of results.
+function setQueryParam(name, value) {
+    const url = new URL(window.location.href);
+    if (value) {
+        url.searchParams.set(name, value);
+    } else {
+        url.searchParams.delete(name);
+    }
+    url.searchParams.delete('page');
+    window.location.href = url.toString();
+}
+
+const modal = document.getElementById('modal');
+const closeModal = document.getElementById('close-modal');
+
+// The button that opens the modal is replaced by live updates, so it is
+// looked up whenever it is needed.
+const showModalBtn = () => document.getElementById('show-modal-btn');
+
+function hideModal() {
+    modal.classList.remove('show');
+    if (showModalBtn()) {
+        showModalBtn().focus();
+    }
+}
+
+closeModal.addEventListener('click', hideModal);
+
+window.addEventListener('click', (e) => {
+    if (e.target == modal) {
+        hideModal();
+    }
+});
+
+document.addEventListener('keydown', function(event) {
+    if (event.key === 'Escape') {
+        hideModal();
+    }
+
+    if (modal.classList.contains('show')) {
+        const focusableElements = modal.querySelectorAll('button, [href], input, select, textarea, [tabindex]:not([tabindex="-1"])');
+        const firstElement = focusableElements[0];
+        const lastElement = focusableElements[focusableElements.length - 1];
+
+        if (event.key === 'Tab') {
+            if (event.shiftKey) {
+                if (document.activeElement === firstElement) {
+                    event.preventDefault();
+                    lastElement.focus();
+                }
+            } else {
+                if (document.activeElement === lastElement) {
+                    event.preventDefault();
+                    firstElement.focus();
+                }
+            }
+        }
+    }
+});
+
+// Clicks are handled on the document, so that results swapped in by live
+// updates work without binding them again.
+document.addEventListener('click', (event) => {
+    if (event.target.closest('#show-modal-btn')) {
+        modal.classList.add('show');
+        document.getElementById('close-modal').focus();
+        return;
+    }
+    const box = event.target.closest('.color-box, .matrix-swatch');
+    if (!box) {
+        return;
+    }
+    event.preventDefault();
+    const parentPair = box.parentElement;
+    const closeLabel = closeModal.getAttribute('aria-label');
+    const html = parentPair.querySelector('.contrast-info').innerHTML;
+
+    const modalContent = document.querySelector('#modal-content');
+    modalContent.innerHTML = '<button id="close-modal" aria-label="' + closeLabel + '">&times;</button>' + html;
+
+    const newCloseModal = document.getElementById('close-modal');
+    newCloseModal.addEventListener('click', hideModal);
+
+    modal.classList.add('show');
+    newCloseModal.focus();
+});
+
+// Palette editor and history. Every change is sent with the palette's
+// ETag, so an edit made against an older version is refused and the page
+// reloads instead. Changes are recorded under the name kept in
+// localStorage, with the optional change note.
+
+// Sends a change made in panel, then loads next so the results are
+// recomputed.
+async function savePalette(panel, path, method, body, next) {
+    const url = new URL(path, window.location.origin);
+    const author = localStorage.getItem('paletteAuthor');
+    if (author) {
+        url.searchParams.set('author', author);
+    }
+    const paletteMessage = document.getElementById('palette-message');
+    if (paletteMessage && paletteMessage.value.trim()) {
+        url.searchParams.set('message', paletteMessage.value.trim());
+    }
+    const headers = { 'Content-Type': 'application/json' };
+    if (panel.dataset.etag) {
+        headers['If-Match'] = panel.dataset.etag;
+    }
+    const response = await fetch(url, {
+        method: method,
+        headers: headers,
+        body: body ? JSON.stringify(body) : undefined,
+    });
+    if (response.status === 412) {
+        showToast(panel.dataset.conflict);
+        setTimeout(() => window.location.reload(), 1500);
+        return;
+    }
+    if (!response.ok) {
+        const problem = await response.json().catch(() => ({}));
+        showToast(panel.dataset.failed + (problem.detail ? ': ' + problem.detail : ''));
+        return;
+    }
+    next.hash = panel.id;
+    if (next.pathname + next.search === window.location.pathname + window.location.search) {
+        window.location.hash = panel.id;
+        window.location.reload();
+    } else {
+        window.location.assign(next.toString());
+    }
+}
+
+const paletteURL = panel => document.body.dataset.base + '/api/palettes/' + encodeURIComponent(panel.dataset.palette);
+
+// Binds the palette panels that are not bound yet. Live updates replace
+// the panels, so this runs again after each of them.
+function bindPalettePanels() {
+    const paletteEditor = document.getElementById('palette-editor');
+    const paletteHistory = document.getElementById('palette-history');
+    const here = () => new URL(window.location.href);
+
+    if (paletteEditor && !paletteEditor.dataset.bound) {
+        paletteEditor.dataset.bound = 'true';
+        const paletteAuthor = document.getElementById('palette-author');
+        if (paletteAuthor) {
+            paletteAuthor.value = localStorage.getItem('paletteAuthor') || '';
+            paletteAuthor.addEventListener('change', function() {
+                localStorage.setItem('paletteAuthor', this.value.trim());
+            });
+        }
+
+        const colorURL = (theme, name) => paletteURL(paletteEditor) + '/themes/' + theme + '/colors/' + encodeURIComponent(name);
+
+        paletteEditor.querySelectorAll('tr[data-theme]').forEach(row => {
+            const url = colorURL(row.dataset.theme, row.dataset.name);
+            row.querySelector('input[type=color]').addEventListener('change', function() {
+                savePalette(paletteEditor, url, 'PUT', { hex: this.value }, here());
+            });
+            row.querySelector('.palette-delete').addEventListener('click', () => {
+                savePalette(paletteEditor, url, 'DELETE', null, here());
+            });
+        });
+
+        document.getElementById('palette-add').addEventListener('submit', function(event) {
+            event.preventDefault();
+            const form = new FormData(this);
+            savePalette(paletteEditor, colorURL(form.get('theme'), form.get('name').trim()), 'PUT', { hex: form.get('hex') }, here());
+        });
+    }
+
+    if (paletteHistory && !paletteHistory.dataset.bound) {
+        paletteHistory.dataset.bound = 'true';
+        paletteHistory.querySelectorAll('.palette-rollback').forEach(button => {
+            button.addEventListener('click', () => {
+                const next = new URL(window.location.href);
+                next.searchParams.delete('version');
+                savePalette(paletteHistory, paletteURL(paletteHistory) + '/versions/' + button.dataset.version + '/rollback', 'POST', null, next);
+            });
+        });
+    }
+}
+
+['palette-editor', 'palette-history'].forEach(id => {
+    const panel = document.getElementById(id);
+    if (panel && window.location.hash === '#' + id) {
+        panel.open = true;
+    }
+});
+bindPalettePanels();
+
+// Color picker. Each pick is checked by the server, which answers with the
+// WCAG levels, the APCA contrast, color vision deficiency previews, and the
+// nearest colors that pass. A picked pair can be saved into the palette.
+const colorPicker = document.getElementById('color-picker');
+const fgColorPicker = document.getElementById('foreground-color');
+const bgColorPicker = document.getElementById('background-color');
+let pickerRequest = null;
+let pickerTimer = null;
+
+async function checkPickedPair() {
+    if (pickerRequest) {
+        pickerRequest.abort();
+    }
+    pickerRequest = new AbortController();
+    const url = new URL(colorPicker.dataset.check, window.location.origin);
+    url.searchParams.set('foreground', fgColorPicker.value);
+    url.searchParams.set('background', bgColorPicker.value);
+    try {
+        const response = await fetch(url, { signal: pickerRequest.signal });
+        const report = await response.json();
+        if (!response.ok) {
+            showToast(colorPicker.dataset.failed + (report.detail ? ': ' + report.detail : ''));
+            return;
+        }
+        renderPickedPair(report);
+    } catch (err) {
+        if (err.name !== 'AbortError') {
+            showToast(colorPicker.dataset.failed);
+        }
+    }
+}
+
+function renderPickedPair(report) {
+    const field = name => colorPicker.querySelector('[data-field="' + name + '"]');
+    const paint = (sample, fg, bg) => {
+        sample.style.color = fg;
+        sample.style.backgroundColor = bg;
+    };
+
+    field('contrastRatio').textContent = report.contrastRatio.toFixed(2);
+    field('contrastRatio').title = String(report.contrastRatioRaw);
+    ['levelSmallText', 'levelLargeText', 'levelNonText'].forEach(name => {
+        field(name).textContent = report[name];
+    });
+    field('lc').textContent = report.apca.lc.toFixed(1);
+    field('lc').title = String(report.apca.lcRaw);
+    report.apca.checks.forEach(check => {
+        const item = colorPicker.querySelector('[data-use="' + check.use + '"]');
+        item.className = check.pass ? 'pass' : 'fail';
+        item.querySelector('span').textContent = 'Lc ' + check.required + ' ' + (check.pass ? colorPicker.dataset.pass : colorPicker.dataset.fail);
+    });
+    paint(document.getElementById('picker-sample'), report.foregroundHex, report.backgroundHex);
+
+    report.simulations.forEach(sim => {
+        const figure = colorPicker.querySelector('[data-cvd="' + sim.type + '"]');
+        paint(figure.querySelector('.picker-sample'), sim.foregroundHex, sim.backgroundHex);
+        figure.querySelector('figcaption span').textContent = sim.contrastRatio.toFixed(2) + ':1 ' + sim.category;
+    });
+
+    const suggestions = document.getElementById('picker-suggestions');
+    suggestions.replaceChildren(...report.suggestions.map(suggestion => {
+        const button = document.createElement('button');
+        button.type = 'button';
+        button.dataset.role = suggestion.role;
+        button.dataset.hex = suggestion.hex;
+        button.style.setProperty('--swatch', suggestion.hex);
+        button.textContent = colorPicker.dataset.suggestion
+            .replace('{level}', suggestion.level)
+            .replace('{role}', colorPicker.dataset[suggestion.role])
+            .replace('{hex}', suggestion.hex)
+            .replace('{ratio}', suggestion.contrastRatio.toFixed(2));
+        const item = document.createElement('li');
+        item.append(button);
+        return item;
+    }));
+    document.getElementById('picker-passes').hidden = report.levelSmallText !== 'AAA';
+}
+
+// Checks the pair once the picker has been still for a moment, as dragging
+// across the color well fires input continuously.
+function schedulePickerCheck() {
+    clearTimeout(pickerTimer);
+    pickerTimer = setTimeout(checkPickedPair, 150);
+}
+
+fgColorPicker.addEventListener('input', schedulePickerCheck);
+bgColorPicker.addEventListener('input', schedulePickerCheck);
+
+document.getElementById('picker-suggestions').addEventListener('click', event => {
+    const button = event.target.closest('button[data-hex]');
+    if (!button) {
+        return;
+    }
+    (button.dataset.role === 'foreground' ? fgColorPicker : bgColorPicker).value = button.dataset.hex;
+    checkPickedPair();
+});
+
+// Saves the picked pair as a light and a dark color in one version, so
+// the palette is replaced whole with the ETag of the editor.
+const pickerSave = document.getElementById('picker-save');
+if (pickerSave) {
+    pickerSave.addEventListener('submit', async function(event) {
+        event.preventDefault();
+        const paletteEditor = document.getElementById('palette-editor');
+        const form = new FormData(this);
+        const response = await fetch(paletteURL(paletteEditor), { cache: 'no-store' });
+        if (!response.ok) {
+            showToast(paletteEditor.dataset.failed);
+            return;
+        }
+        const palette = await response.json();
+        const body = { light: palette.light || {}, dark: palette.dark || {}, usages: palette.usages };
+        body.light[form.get('foreground').trim()] = fgColorPicker.value;
+        body.dark[form.get('background').trim()] = bgColorPicker.value;
+        savePalette(paletteEditor, paletteURL(paletteEditor), 'PUT', body, new URL(window.location.href));
+    });
+}
+
+checkPickedPair();
+
+// Live updates. The server sends palette-reloaded when the palette is
+// saved or colors.json is written, followed by results-changed when that
+// changed any pair. The page then fetches itself again, swaps in the
+// regions marked data-live, and highlights the pairs that changed. A
+// region with focus is kept, apart from its ETag, so typing is not lost.
+async function refreshResults(change) {
+    const response = await fetch(window.location.href, { headers: { Accept: 'text/html' } });
+    if (!response.ok) {
+        return;
+    }
+    const next = new DOMParser().parseFromString(await response.text(), 'text/html');
+    document.querySelectorAll('[data-live]').forEach(region => {
+        const replacement = next.getElementById(region.id);
+        if (!replacement) {
+            return;
+        }
+        if (region.contains(document.activeElement)) {
+            if (replacement.dataset.etag) {
+                region.dataset.etag = replacement.dataset.etag;
+            }
+            return;
+        }
+        if (region.open) {
+            replacement.open = true;
+        }
+        region.replaceWith(document.adoptNode(replacement));
+    });
+    bindPalettePanels();
+
+    const changed = new Map(change.changed.map(pair => [pair.foregroundName + '\n' + pair.backgroundName, pair]));
+    document.querySelectorAll('.container [data-fg]').forEach(element => {
+        const pair = changed.get(element.dataset.fg + '\n' + element.dataset.bg);
+        if (pair) {
+            element.classList.add(pair.previousCategory === pair.category ? 'pair-updated' : 'level-changed');
+        }
+    });
+    showToast(document.body.dataset.liveUpdated);
+}
+
+if (document.body.dataset.events && window.EventSource) {
+    const events = new EventSource(document.body.dataset.events);
+    events.addEventListener('results-changed', event => {
+        refreshResults(JSON.parse(event.data));
+    });
+}
+
+// Toast Notification Function
+function showToast(message) {
+    const toast = document.getElementById('toast');
+    toast.textContent = message;
+    toast.className = "show";
+    setTimeout(() => { toast.className = toast.className.replace("show", ""); }, 3000);
+}
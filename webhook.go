@@ -0,0 +1,623 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is a URL that is sent every change to a project's palettes.
+type Webhook struct {
+	ID      int64  `json:"id"`
+	Project string `json:"project"`
+	URL     string `json:"url"`
+	// Secret signs deliveries. It is generated unless given and is only
+	// shown when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+	// Palettes limits the webhook to the named palettes; empty means all.
+	Palettes []string `json:"palettes"`
+	// RegressionsOnly skips changes that make no pair or usage rule fail.
+	RegressionsOnly bool      `json:"regressionsOnly,omitempty"`
+	Disabled        bool      `json:"disabled,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Webhook events, sent in the X-Contrast-Event header.
+const (
+	eventPaletteCreated = "palette.created"
+	eventPaletteUpdated = "palette.updated"
+	eventPaletteDeleted = "palette.deleted"
+)
+
+// Delivery states.
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+// A delivery is attempted up to webhookAttempts times, waiting
+// webhookBackoff after the first failure and twice as long after each one
+// that follows. deliveriesKept finished deliveries are kept per webhook.
+// Up to webhookWorkers webhooks are sent to at once.
+const (
+	webhookAttempts = 6
+	webhookBackoff  = 10 * time.Second
+	webhookTimeout  = 10 * time.Second
+	webhookWorkers  = 8
+	deliveriesKept  = 500
+)
+
+// WebhookDelivery is one change sent to a webhook, with the outcome of its
+// latest attempt.
+type WebhookDelivery struct {
+	ID       int64  `json:"id"`
+	Webhook  int64  `json:"webhook"`
+	Event    string `json:"event"`
+	Palette  string `json:"palette"`
+	Version  int    `json:"version"`
+	State    string `json:"state"`
+	Attempts int    `json:"attempts"`
+	// Status is the HTTP status of the latest attempt, 0 when the request
+	// failed.
+	Status      int             `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	NextAttempt *time.Time      `json:"nextAttempt,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// WebhookPayload is the body of a delivery.
+type WebhookPayload struct {
+	Event           string      `json:"event"`
+	Project         string      `json:"project"`
+	Palette         string      `json:"palette"`
+	Version         int         `json:"version"`
+	PreviousVersion int         `json:"previousVersion"`
+	Author          string      `json:"author"`
+	Message         string      `json:"message"`
+	CreatedAt       time.Time   `json:"createdAt"`
+	Summary         LevelCounts `json:"summary"`
+	PreviousSummary LevelCounts `json:"previousSummary"`
+	// Changes lists the colors that were added, removed, or changed.
+	Changes []ColorChange `json:"changes"`
+	// NewFailures are the pairs in the Fail category that were not before,
+	// including pairs of new colors.
+	NewFailures []PairChange `json:"newFailures"`
+	// NewFailingUsages are the usage rules that failed after the change
+	// and passed, or did not exist, before it.
+	NewFailingUsages []UsageResult `json:"newFailingUsages"`
+	// Regression is set when NewFailures or NewFailingUsages is not empty.
+	Regression bool `json:"regression"`
+}
+
+// ColorChange is one color of a palette diff. Before is empty for added
+// colors and After for removed ones.
+type ColorChange struct {
+	Theme  string `json:"theme"`
+	Name   string `json:"name"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// PairChange is a result with the category its pair had before a change,
+// or none for a new pair.
+type PairChange struct {
+	ContrastResult
+	PreviousCategory *Category `json:"previousCategory"`
+}
+
+// diffPalettes compares two versions of a palette.
+func diffPalettes(ctx context.Context, before, after *ColorSets, algorithm string) ([]ColorChange, []PairChange, []UsageResult) {
+	changes := []ColorChange{}
+	for _, theme := range []string{"light", "dark"} {
+		old, cur := before.theme(theme), after.theme(theme)
+		var names []string
+		for name := range old {
+			names = append(names, name)
+		}
+		for name := range cur {
+			if _, ok := old[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			if old[name] != cur[name] {
+				changes = append(changes, ColorChange{Theme: theme, Name: name, Before: old[name], After: cur[name]})
+			}
+		}
+	}
+
+	type pair struct{ fg, bg string }
+	oldResults, _ := contrastResults(ctx, before)
+	categories := map[pair]Category{}
+	for _, r := range oldResults {
+		categories[pair{r.ForegroundName, r.BackgroundName}] = r.Category
+	}
+	newResults, _ := contrastResults(ctx, after)
+	failures := []PairChange{}
+	for _, r := range newResults {
+		if r.Category != CategoryFail {
+			continue
+		}
+		prev, ok := categories[pair{r.ForegroundName, r.BackgroundName}]
+		switch {
+		case !ok:
+			failures = append(failures, PairChange{ContrastResult: r})
+		case prev != CategoryFail:
+			failures = append(failures, PairChange{ContrastResult: r, PreviousCategory: &prev})
+		}
+	}
+
+	oldUsages, _ := evaluateUsages(ctx, before, algorithm)
+	passed := map[string]bool{}
+	for _, u := range oldUsages {
+		passed[u.Name] = u.Pass
+	}
+	newUsages, _ := evaluateUsages(ctx, after, algorithm)
+	failing := []UsageResult{}
+	for _, u := range newUsages {
+		if pass, ok := passed[u.Name]; !u.Pass && (pass || !ok) {
+			failing = append(failing, u)
+		}
+	}
+	return changes, failures, failing
+}
+
+// queueWebhooks queues a delivery of version v to each webhook of its
+// project, in the transaction that records it, so a change is delivered
+// exactly when it is saved.
+func queueWebhooks(ctx context.Context, tx *sql.Tx, v PaletteVersion, change Change) error {
+	hooks, err := listWebhooks(ctx, tx, v.Project)
+	if err != nil {
+		return err
+	}
+	hooks = slices.DeleteFunc(hooks, func(h Webhook) bool {
+		return h.Disabled || len(h.Palettes) > 0 && !slices.Contains(h.Palettes, v.Palette)
+	})
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	project, err := getProject(ctx, tx, v.Project)
+	if err != nil {
+		return err
+	}
+	algorithm := project.Settings.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmWCAG
+	}
+	before := &ColorSets{Light: map[string]string{}, Dark: map[string]string{}}
+	var prevVersion int
+	var prevDeleted bool
+	var colors string
+	err = tx.QueryRowContext(ctx, "SELECT version, deleted, colors FROM versions WHERE project = ? AND palette = ? AND version < ? ORDER BY version DESC LIMIT 1",
+		v.Project, v.Palette, v.Version).Scan(&prevVersion, &prevDeleted, &colors)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal([]byte(colors), before); err != nil {
+			return err
+		}
+	}
+
+	event := eventPaletteUpdated
+	switch {
+	case v.Deleted:
+		event = eventPaletteDeleted
+	case prevVersion == 0 || prevDeleted:
+		event = eventPaletteCreated
+	}
+	p := WebhookPayload{
+		Event:           event,
+		Project:         v.Project,
+		Palette:         v.Palette,
+		Version:         v.Version,
+		PreviousVersion: prevVersion,
+		Author:          change.Author,
+		Message:         change.Message,
+		CreatedAt:       v.CreatedAt,
+		Summary:         v.Summary,
+		PreviousSummary: summarizeLevels(ctx, before),
+	}
+	p.Changes, p.NewFailures, p.NewFailingUsages = diffPalettes(ctx, before, v.Colors, algorithm)
+	p.Regression = len(p.NewFailures)+len(p.NewFailingUsages) > 0
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, h := range hooks {
+		if h.RegressionsOnly && !p.Regression {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO deliveries (webhook, event, palette, version, payload, state, next_attempt, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			h.ID, event, v.Palette, v.Version, string(payload), deliveryPending, now.UnixMilli(), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateWebhook(h *Webhook) error {
+	u, err := url.Parse(h.URL)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: url %q must be an absolute http or https URL", errInvalidWebhook, h.URL)
+	}
+	for _, name := range h.Palettes {
+		if err := validName(name); err != nil {
+			return fmt.Errorf("%w: palettes: %w", errInvalidWebhook, err)
+		}
+	}
+	if h.Palettes == nil {
+		h.Palettes = []string{}
+	}
+	return nil
+}
+
+const webhookColumns = "id, project, url, palettes, regressions_only, disabled, created_at"
+
+func scanWebhook(row interface{ Scan(...any) error }) (Webhook, error) {
+	var h Webhook
+	var palettes, created string
+	if err := row.Scan(&h.ID, &h.Project, &h.URL, &palettes, &h.RegressionsOnly, &h.Disabled, &created); err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal([]byte(palettes), &h.Palettes); err != nil {
+		return h, err
+	}
+	var err error
+	h.CreatedAt, err = time.Parse(time.RFC3339Nano, created)
+	return h, err
+}
+
+func listWebhooks(ctx context.Context, q querier, project string) ([]Webhook, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE project = ? ORDER BY id", project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hooks := []Webhook{}
+	for rows.Next() {
+		h, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, rows.Err()
+}
+
+// Webhooks returns the webhooks of a project, without their secrets.
+func (s *Store) Webhooks(ctx context.Context, project string) ([]Webhook, error) {
+	if _, err := s.Project(ctx, project); err != nil {
+		return nil, err
+	}
+	return listWebhooks(ctx, s.db, project)
+}
+
+// Webhook returns a webhook of a project, without its secret.
+func (s *Store) Webhook(ctx context.Context, project string, id int64) (Webhook, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE project = ? AND id = ?", project, id)
+	h, err := scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return h, fmt.Errorf("webhook %d: %w", id, errNotFound)
+	}
+	return h, err
+}
+
+// CreateWebhook adds a webhook to a project, generating its secret unless
+// one is given, and returns it with the secret.
+func (s *Store) CreateWebhook(ctx context.Context, h Webhook) (Webhook, error) {
+	if err := validateWebhook(&h); err != nil {
+		return h, err
+	}
+	if _, err := s.Project(ctx, h.Project); err != nil {
+		return h, err
+	}
+	if h.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return h, err
+		}
+		h.Secret = hex.EncodeToString(secret)
+	}
+	palettes, err := json.Marshal(h.Palettes)
+	if err != nil {
+		return h, err
+	}
+	h.CreatedAt = time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `INSERT INTO webhooks (project, url, secret, palettes, regressions_only, disabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		h.Project, h.URL, h.Secret, string(palettes), h.RegressionsOnly, h.Disabled, h.CreatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return h, err
+	}
+	h.ID, err = res.LastInsertId()
+	return h, err
+}
+
+// UpdateWebhook changes a webhook, keeping its secret unless a new one is
+// given.
+func (s *Store) UpdateWebhook(ctx context.Context, h Webhook) (Webhook, error) {
+	if err := validateWebhook(&h); err != nil {
+		return h, err
+	}
+	palettes, err := json.Marshal(h.Palettes)
+	if err != nil {
+		return h, err
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE webhooks SET url = ?, palettes = ?, regressions_only = ?, disabled = ?,
+		secret = CASE WHEN ? = '' THEN secret ELSE ? END WHERE project = ? AND id = ?`,
+		h.URL, string(palettes), h.RegressionsOnly, h.Disabled, h.Secret, h.Secret, h.Project, h.ID)
+	if err != nil {
+		return h, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return h, err
+	} else if n == 0 {
+		return h, fmt.Errorf("webhook %d: %w", h.ID, errNotFound)
+	}
+	s.wakeWebhooks()
+	return s.Webhook(ctx, h.Project, h.ID)
+}
+
+// DeleteWebhook removes a webhook with its deliveries.
+func (s *Store) DeleteWebhook(ctx context.Context, project string, id int64) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM webhooks WHERE project = ? AND id = ?", project, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	return fmt.Errorf("webhook %d: %w", id, errNotFound)
+}
+
+// Deliveries returns the newest deliveries of a webhook, up to limit.
+func (s *Store) Deliveries(ctx context.Context, project string, id int64, limit int) ([]WebhookDelivery, error) {
+	if _, err := s.Webhook(ctx, project, id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, webhook, event, palette, version, state, attempts, status, error, next_attempt, created_at, updated_at, payload
+		FROM deliveries WHERE webhook = ? ORDER BY id DESC LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		var next int64
+		var created, updated, payload string
+		err := rows.Scan(&d.ID, &d.Webhook, &d.Event, &d.Palette, &d.Version, &d.State, &d.Attempts, &d.Status, &d.Error, &next, &created, &updated, &payload)
+		if err != nil {
+			return nil, err
+		}
+		if d.State == deliveryPending {
+			t := time.UnixMilli(next).UTC()
+			d.NextAttempt = &t
+		}
+		if d.CreatedAt, err = time.Parse(time.RFC3339Nano, created); err != nil {
+			return nil, err
+		}
+		if d.UpdatedAt, err = time.Parse(time.RFC3339Nano, updated); err != nil {
+			return nil, err
+		}
+		d.Payload = json.RawMessage(payload)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// wakeWebhooks tells the sender that deliveries may be due.
+func (s *Store) wakeWebhooks() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SendWebhooks sends due deliveries until ctx is done. Deliveries are kept
+// in the database, so the ones pending when the server stops are sent
+// after it starts again.
+func (s *Store) SendWebhooks(ctx context.Context) {
+	sender := &webhookSender{client: &http.Client{Timeout: webhookTimeout}, busy: map[int64]bool{}}
+	defer sender.wg.Wait()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if err := s.sendDue(ctx, sender); err != nil && ctx.Err() == nil {
+			slog.Error("Failed to send webhooks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// webhookSender tracks the webhooks being sent to. Each has one goroutine,
+// so its deliveries stay in order, and a slow endpoint holds up no other.
+type webhookSender struct {
+	client *http.Client
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	busy map[int64]bool
+}
+
+type dueDelivery struct {
+	id, webhook    int64
+	url, secret    string
+	event, payload string
+	attempts       int
+}
+
+// sendDue starts sending the due deliveries of every webhook that is not
+// already being sent to, up to webhookWorkers at once.
+func (s *Store) sendDue(ctx context.Context, sender *webhookSender) error {
+	sender.mu.Lock()
+	var busy []any
+	for id := range sender.busy {
+		busy = append(busy, id)
+	}
+	sender.mu.Unlock()
+	if len(busy) >= webhookWorkers {
+		return nil
+	}
+	skip := ""
+	if len(busy) > 0 {
+		skip = "AND d.webhook NOT IN (?" + strings.Repeat(", ?", len(busy)-1) + ")"
+	}
+	args := append([]any{deliveryPending, time.Now().UnixMilli()}, busy...)
+	rows, err := s.db.QueryContext(ctx, `SELECT d.id, d.webhook, w.url, w.secret, d.event, d.payload, d.attempts
+		FROM deliveries d JOIN webhooks w ON w.id = d.webhook
+		WHERE d.state = ? AND d.next_attempt <= ? AND w.disabled = 0 `+skip+` ORDER BY d.id LIMIT 50`,
+		args...)
+	if err != nil {
+		return err
+	}
+	var order []int64
+	due := map[int64][]dueDelivery{}
+	for rows.Next() {
+		var d dueDelivery
+		if err := rows.Scan(&d.id, &d.webhook, &d.url, &d.secret, &d.event, &d.payload, &d.attempts); err != nil {
+			rows.Close()
+			return err
+		}
+		if _, ok := due[d.webhook]; !ok {
+			order = append(order, d.webhook)
+		}
+		due[d.webhook] = append(due[d.webhook], d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range order {
+		sender.mu.Lock()
+		if sender.busy[id] || len(sender.busy) >= webhookWorkers {
+			sender.mu.Unlock()
+			continue
+		}
+		sender.busy[id] = true
+		sender.mu.Unlock()
+		sender.wg.Add(1)
+		go func() {
+			defer sender.wg.Done()
+			if err := s.sendWebhookDue(ctx, sender.client, due[id]); err != nil && ctx.Err() == nil {
+				slog.Error("Failed to send webhooks", "webhook", id, "error", err)
+			}
+			sender.mu.Lock()
+			delete(sender.busy, id)
+			sender.mu.Unlock()
+			// More may have come due while this webhook was busy.
+			s.wakeWebhooks()
+		}()
+	}
+	return nil
+}
+
+// sendWebhookDue sends the due deliveries of one webhook in order, stopping
+// at the first that fails.
+func (s *Store) sendWebhookDue(ctx context.Context, client *http.Client, due []dueDelivery) error {
+	for _, d := range due {
+		status, sendErr := deliver(ctx, client, d)
+		d.attempts++
+		now := time.Now().UTC()
+		state, next, msg := deliveryDelivered, now, ""
+		if sendErr != nil {
+			msg = sendErr.Error()
+			state = deliveryFailed
+			if d.attempts < webhookAttempts {
+				state = deliveryPending
+				next = now.Add(webhookBackoff << (d.attempts - 1))
+			}
+			slog.Warn("Webhook delivery failed", "webhook", d.webhook, "delivery", d.id, "attempt", d.attempts, "status", status, "error", sendErr)
+		}
+		_, err := s.db.ExecContext(ctx, "UPDATE deliveries SET state = ?, attempts = ?, status = ?, error = ?, next_attempt = ?, updated_at = ? WHERE id = ?",
+			state, d.attempts, status, msg, next.UnixMilli(), now.Format(time.RFC3339Nano), d.id)
+		if err != nil {
+			return err
+		}
+		if state != deliveryPending {
+			_, err := s.db.ExecContext(ctx, `DELETE FROM deliveries WHERE webhook = ? AND state != ? AND id <= (
+				SELECT id FROM deliveries WHERE webhook = ? AND state != ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+				d.webhook, deliveryPending, d.webhook, deliveryPending, deliveriesKept)
+			if err != nil {
+				return err
+			}
+		}
+		if state == deliveryPending {
+			// Hold the later deliveries until the retry, keeping them in
+			// order and the endpoint to one attempt per backoff.
+			_, err := s.db.ExecContext(ctx, "UPDATE deliveries SET next_attempt = MAX(next_attempt, ?) WHERE webhook = ? AND state = ? AND id > ?",
+				next.UnixMilli(), d.webhook, deliveryPending, d.id)
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookSignature signs a delivery: the hex HMAC-SHA256, under the
+// webhook's secret, of the timestamp, a dot, and the body.
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver makes one attempt at a delivery. Any 2xx status is success.
+func deliver(ctx context.Context, client *http.Client, d dueDelivery) (int, error) {
+	body := []byte(d.payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "contrast-checker-webhooks")
+	req.Header.Set("X-Contrast-Event", d.event)
+	req.Header.Set("X-Contrast-Delivery", strconv.FormatInt(d.id, 10))
+	req.Header.Set("X-Contrast-Timestamp", timestamp)
+	req.Header.Set("X-Contrast-Signature", webhookSignature(d.secret, timestamp, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.StatusCode, nil
+}